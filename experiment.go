@@ -0,0 +1,258 @@
+package main
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/sim"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// configSettings are the flags that set the config, varying
+// any other would run the same shop at every point
+var configSettings = map[string]bool{}
+
+// workloadSettings make the customers, a workload's
+// customers are the same whatever they're set to
+var workloadSettings = map[string]bool{
+	"customer-count": true,
+	"arrival-rate":   true,
+}
+
+// checkSetting is an error for a setting that wouldn't
+// change the shop the config builds
+func checkSetting(config *sim.Config, name string) error {
+	if !configSettings[name] {
+		return fmt.Errorf("no %s setting of the shop", name)
+	}
+	if len(config.Workload) > 0 && workloadSettings[name] {
+		return fmt.Errorf("%s doesn't change a workload's customers", name)
+	}
+
+	return nil
+}
+
+// sweep runs the config with every combination of the settings
+// and writes a row for each to the out file
+func sweep(config *sim.Config, settings []string, replications int, parallel int, outFile string, format string) error {
+	writeTable := sim.WriteCSV
+	switch format {
+	case "csv":
+	case "json":
+		writeTable = sim.WriteJSON
+	default:
+		return fmt.Errorf("unknown sweep format %q", format)
+	}
+
+	dimensions, points, err := sweepPoints(config, settings)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if outFile != "" {
+		file, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := quietShops(); err != nil {
+		return err
+	}
+	rows, err := sim.Sweep{
+		Points:       points,
+		Replications: replications,
+		Parallel:     parallel,
+		Progress:     showProgress(dimensions),
+	}.Run()
+	if err != nil {
+		return err
+	}
+
+	return writeTable(out, dimensions, rows)
+}
+
+// optimize looks for the cheapest combination of the settings with
+// a p95 wait within the SLA and prints it with the ones it tried
+func optimize(config *sim.Config, settings []string, costs sim.Costs, sla time.Duration, replications int, parallel int) error {
+	dimensions, points, err := sweepPoints(config, settings)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if err := quietShops(); err != nil {
+		return err
+	}
+	best, explored, err := sim.Optimizer{
+		Points:       points,
+		Costs:        costs,
+		SLA:          sla,
+		Replications: replications,
+		Parallel:     parallel,
+		Progress:     showProgress(dimensions),
+	}.Run()
+	if err != nil {
+		return err
+	}
+
+	if best == nil {
+		fmt.Fprintf(out, "Nothing tried has a p95 wait within %s\n", simDuration(sla))
+	} else {
+		fmt.Fprintf(out, "Cheapest with a p95 wait within %s: %s\n", simDuration(sla), describe(dimensions, best.Settings))
+		fmt.Fprintf(out, "  cost %.2f, p95 wait %s, %.1f orders per hour\n", best.Cost, simDuration(best.Row.P95), best.Row.Throughput)
+	}
+
+	// the frontier is marked with a *, the candidates skipped
+	// had less of everything than one that missed
+	fmt.Fprintln(out, "\nExplored, * is the cost and p95 wait frontier")
+	fmt.Fprintf(out, "  %10s %10s %12s  %-8s %s\n", "cost", "p95 wait", "orders/hour", "sla", "settings")
+	for _, candidate := range explored {
+		frontier := " "
+		if candidate.Frontier {
+			frontier = "*"
+		}
+		result := "skipped"
+		p95 := "-"
+		throughput := "-"
+		if candidate.Ran {
+			result = "missed"
+			if candidate.MeetsSLA {
+				result = "met"
+			}
+			p95 = simDuration(candidate.Row.P95).String()
+			throughput = fmt.Sprintf("%.1f", candidate.Row.Throughput)
+		}
+		fmt.Fprintf(out, "%s %10.2f %10s %12s  %-8s %s\n", frontier, candidate.Cost, p95, throughput, result, describe(dimensions, candidate.Settings))
+	}
+
+	return nil
+}
+
+// replicate runs the config the replications and prints
+// each statistic's mean and confidence interval
+func replicate(config *sim.Config, replications int, parallel int) error {
+	out := os.Stdout
+	if err := quietShops(); err != nil {
+		return err
+	}
+	results, err := sim.Replications{
+		Config:   *config,
+		Count:    replications,
+		Parallel: parallel,
+		Progress: func(done int, total int, err error) {
+			fmt.Fprintf(os.Stderr, "Run %d of %d\n", done, total)
+		},
+	}.Run()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d replications from seed %d\n", replications, config.Seed)
+	return sim.WriteEstimates(out, sim.Summarize(results))
+}
+
+// compare runs the config and the config with the settings changed with
+// the same seeds and prints the difference they make
+func compare(config *sim.Config, settings []string, replications int, parallel int) error {
+	base := *config
+	for _, setting := range settings {
+		name, value, found := strings.Cut(setting, "=")
+		if !found {
+			return fmt.Errorf("%q isn't a setting=value of the shop to change", setting)
+		}
+		if err := checkSetting(config, name); err != nil {
+			return err
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("%s: %w", setting, err)
+		}
+	}
+	if len(settings) == 0 {
+		return errors.New("needs settings to change, like barista-count=3")
+	}
+
+	out := os.Stdout
+	if err := quietShops(); err != nil {
+		return err
+	}
+	comparisons, err := sim.ComparePaired(base, *config, replications, parallel,
+		func(done int, total int, err error) {
+			fmt.Fprintf(os.Stderr, "Run %d of %d\n", done, total)
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d paired replications from seed %d, other is %s\n", replications, base.Seed, strings.Join(settings, " "))
+	fmt.Fprintln(out, "* the difference is outside the noise")
+	return sim.WriteComparisons(out, comparisons)
+}
+
+// sweepPoints reads the settings to vary, they're flags so setting them
+// changes the config, and makes a point for every combination of them
+func sweepPoints(config *sim.Config, settings []string) ([]sim.Dimension, []sim.Point, error) {
+	dimensions := []sim.Dimension{}
+	for _, setting := range settings {
+		dimension, err := sim.ParseDimension(setting)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkSetting(config, dimension.Name); err != nil {
+			return nil, nil, err
+		}
+		dimensions = append(dimensions, dimension)
+	}
+	if len(dimensions) == 0 {
+		return nil, nil, errors.New("needs settings to vary, like barista-count=1..4")
+	}
+
+	points := []sim.Point{}
+	for _, combination := range sim.Combinations(dimensions) {
+		for i, value := range combination {
+			if err := flag.Set(dimensions[i].Name, value); err != nil {
+				return nil, nil, fmt.Errorf("%s=%s: %w", dimensions[i].Name, value, err)
+			}
+		}
+		points = append(points, sim.Point{Settings: combination, Config: *config})
+	}
+
+	return dimensions, points, nil
+}
+
+// quietShops drops the shops' logs, they would bury the results.
+// Errors go to stderr so they're still seen.
+func quietShops() error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	os.Stdout = devNull
+	return nil
+}
+
+// showProgress writes each run to stderr as it finishes
+func showProgress(dimensions []sim.Dimension) func(int, int, sim.Point, error) {
+	return func(done int, total int, point sim.Point, err error) {
+		fmt.Fprintf(os.Stderr, "Run %d of %d %s\n", done, total, describe(dimensions, point.Settings))
+	}
+}
+
+func describe(dimensions []sim.Dimension, values []string) string {
+	settings := []string{}
+	for i, value := range values {
+		settings = append(settings, dimensions[i].Name+"="+value)
+	}
+	return strings.Join(settings, " ")
+}
+
+// simDuration is a real duration as simulated time, to a tenth of a second
+func simDuration(d time.Duration) time.Duration {
+	return models.SimDuration(d).Round(100 * time.Millisecond)
+}
@@ -2,117 +2,420 @@ package main
 
 import (
 	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/rpc"
+	"blreynolds4/coffeeshop/server"
+	"blreynolds4/coffeeshop/sim"
+	"blreynolds4/coffeeshop/tui"
 	"flag"
 	"fmt"
-	"math/rand"
-	"sync"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strings"
 	"time"
-)
-
-const (
-	RegularSizeOunces = 8
-	LargeSizeOunces   = 12
 
-	// common ratio is 1 gram coffee per 17ml of water
-	// 17 ml is .6 ounces, scale that to 1 ounce
-	// is roughly 28ml so we'll call it 2 grams per ounce
-	RegularBrewingRatioGramsPerOunce = 2
-	StrongBrewingRatioGramsPerOunce  = 4
+	"google.golang.org/grpc"
 )
 
 func main() {
-	var cliGrinderCount int
-	var cliBrewerCount int
-	var cliKioskCount int
-	var cliBaristaCount int
-	var cliCustomerCount int
-	var cliBaristaOrderCount int
-
-	flag.IntVar(&cliGrinderCount, "grinder-count", 1, "The count of grinders in the coffee shop")
-	flag.IntVar(&cliBrewerCount, "brewer-count", 1, "The count of brewers in the coffee shop")
-	flag.IntVar(&cliKioskCount, "kiosk-count", 1, "The count of ordering kiosks in the coffee shop")
-	flag.IntVar(&cliBaristaCount, "barista-count", 1, "The count of baristas working in the coffee shop")
-	flag.IntVar(&cliCustomerCount, "customer-count", 1, "The count of customers ordering in the coffee shop")
-	flag.IntVar(&cliBaristaOrderCount, "barista-order-count", 5, "The maximum number of orders a barista can work on at a time")
-
-	// parse command line
-	flag.Parse()
-
-	// create a menu for the coffee shop
-	// this could be proivded via a config file
-	menu := models.Menu{
-		models.MenuItem{
-			Name:        "Regular",
-			Size:        RegularSizeOunces,
-			CoffeeRatio: RegularBrewingRatioGramsPerOunce,
-		},
-		models.MenuItem{
-			Name:        "Regular Strong",
-			Size:        RegularSizeOunces,
-			CoffeeRatio: StrongBrewingRatioGramsPerOunce,
-		},
-		models.MenuItem{
-			Name:        "Large Regular",
-			Size:        LargeSizeOunces,
-			CoffeeRatio: RegularBrewingRatioGramsPerOunce,
-		},
-		models.MenuItem{
-			Name:        "Large Strong",
-			Size:        LargeSizeOunces,
-			CoffeeRatio: StrongBrewingRatioGramsPerOunce,
-		},
-	}
-
-	// Create pool of grinders.  They grind in grams per second
-	grinders := models.NewGrinderPool()
-	for i := 0; i < cliGrinderCount; i++ {
-		// create a grinder with up to 10 grams per second speed
-		grinders.AddGrinder(models.NewGrinder(rand.Intn(10)))
-	}
-
-	// Create pool of brewers.  They brew in ounces per second
-	brewers := models.NewBrewerPool()
-	for i := 0; i < cliBrewerCount; i++ {
-		// create brewer with up to LargeSizeOunces per second
-		brewers.AddBrewer(models.NewBrewer(rand.Intn(LargeSizeOunces)))
+	config := sim.DefaultConfig()
+
+	var cliAddr string
+	var cliGRPCAddr string
+	var cliMetricsAddr string
+	var cliTraceFile string
+	var cliJournalFile string
+	var cliRecover bool
+	var cliDiscardJournal bool
+	var cliTUI bool
+	var cliQueueing bool
+	var cliScenarioFile string
+	var cliWorkloadFile string
+	var cliRecordFile string
+	var cliReplications int
+	var cliParallel int
+	var cliSweepOut string
+	var cliSweepFormat string
+	var cliArrivalRate float64
+	var cliSLASeconds int
+	costs := sim.Costs{}
+
+	flag.IntVar(&config.GrinderCount, "grinder-count", config.GrinderCount, "The count of grinders in the coffee shop")
+	flag.IntVar(&config.DecafGrinderCount, "decaf-grinder-count", config.DecafGrinderCount, "The count of grinders dedicated to decaf beans")
+	flag.IntVar(&config.BrewerCount, "brewer-count", config.BrewerCount, "The count of drip brewers in the coffee shop")
+	flag.IntVar(&config.BrewerCups, "brewer-cups", config.BrewerCups, "The count of cups each drip brewer can make at once")
+	flag.IntVar(&config.PourOverCount, "pour-over-count", config.PourOverCount, "The count of pour over stations in the coffee shop")
+	flag.IntVar(&config.FrenchPressCount, "french-press-count", config.FrenchPressCount, "The count of french presses in the coffee shop")
+	flag.IntVar(&config.AeroPressCount, "aeropress-count", config.AeroPressCount, "The count of aeropresses in the coffee shop")
+	flag.IntVar(&config.BatchBrewerCount, "batch-brewer-count", config.BatchBrewerCount, "The count of batch brewers filling carafes in the coffee shop")
+	flag.IntVar(&config.CarafeCups, "carafe-cups", config.CarafeCups, "The maximum number of cups in a carafe")
+	flag.IntVar(&config.CarafeHoldSeconds, "carafe-hold-seconds", config.CarafeHoldSeconds, "The number of seconds a carafe is held before it is dumped")
+	flag.IntVar(&config.KioskCount, "kiosk-count", config.KioskCount, "The count of ordering kiosks in the coffee shop")
+	flag.IntVar(&config.BaristaCount, "barista-count", config.BaristaCount, "The count of baristas working in the coffee shop")
+	flag.IntVar(&config.CustomerCount, "customer-count", config.CustomerCount, "The count of customers ordering in the coffee shop")
+	flag.IntVar(&config.BaristaOrderCount, "barista-order-count", config.BaristaOrderCount, "The maximum number of orders a barista can work on at a time")
+
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "The seed for the random parts of the simulation, 0 picks one from the clock")
+	flag.Float64Var(&config.TimingSpread, "timing-spread", config.TimingSpread, "The lognormal sigma that grind and brew times vary by, 0 is steady")
+	flag.IntVar(&config.WarmUpSeconds, "warm-up-seconds", config.WarmUpSeconds, "The number of seconds a cold grinder or brewer takes to warm up")
+	flag.IntVar(&config.IdleSeconds, "idle-seconds", config.IdleSeconds, "The number of idle seconds before a machine is cold again, 0 stays warm")
+	flag.Float64Var(&config.BaristaSpeed, "barista-speed", config.BaristaSpeed, "How fast the baristas work, 2 is twice as fast")
+	flag.Float64Var(&config.BaristaErrorRate, "barista-error-rate", config.BaristaErrorRate, "The chance a barista gets a step wrong and has to remake the drink")
+	flag.IntVar(&config.TraineeCount, "trainee-count", config.TraineeCount, "The count of baristas that are trainees, only trained on the grinder and drip")
+	flag.Float64Var(&config.OpenHours, "open-hours", config.OpenHours, "The hours the shop is open with customers arriving through the day, 0 has them all arrive at once")
+	flag.Float64Var(&config.ShiftHours, "shift-hours", config.ShiftHours, "The hours in a barista's shift when the shop has open hours")
+	flag.IntVar(&config.BreakMinutes, "break-minutes", config.BreakMinutes, "The minutes of break in the middle of each shift when the shop has open hours")
+	flag.IntVar(&config.AutoscaleMax, "autoscale-max", config.AutoscaleMax, "The most baristas the autoscaler can have working, 0 turns it off")
+	flag.IntVar(&config.AutoscaleQueue, "autoscale-queue", config.AutoscaleQueue, "The count of orders in the shop before the autoscaler adds a barista")
+	flag.IntVar(&config.AutoscaleWaitSeconds, "autoscale-wait-seconds", config.AutoscaleWaitSeconds, "The average wait in seconds before the autoscaler adds a barista")
+
+	// the flags so far set the config, they're the settings that
+	// can be swept or compared, the rest don't change the shop
+	flag.VisitAll(func(f *flag.Flag) {
+		configSettings[f.Name] = true
+	})
+
+	flag.StringVar(&cliAddr, "addr", ":8080", "The address to listen on with the serve command")
+	flag.BoolVar(&cliTUI, "tui", false, "Show a live view of the shop instead of the log while the simulation runs")
+	flag.BoolVar(&cliQueueing, "queueing", false, "Compare the grinder and brewer waits and utilization to the M/M/c and M/G/c queueing models after the run")
+	flag.StringVar(&cliScenarioFile, "scenario", "", "The scenario file of actions to play against the run and the expectations it has to meet, a failure exits with 1")
+	flag.StringVar(&cliWorkloadFile, "workload", "", "The .csv or .jsonl file of customers to send in instead of the customer count's random ones")
+	flag.StringVar(&cliRecordFile, "record", "", "The .csv or .jsonl file to write the run's customers to, -workload replays them")
+	flag.StringVar(&cliTraceFile, "trace-file", "", "The file to write the spans of each order to as JSON lines, - is stdout")
+	flag.StringVar(&cliMetricsAddr, "metrics-addr", "", "The address to serve /metrics on while a simulation runs, empty doesn't serve it")
+	flag.StringVar(&cliJournalFile, "journal", "", "The file to journal the orders and their status changes to, empty doesn't keep one")
+	flag.BoolVar(&cliRecover, "recover", false, "Finish the orders left in the journal by the last run before taking new ones")
+	flag.BoolVar(&cliDiscardJournal, "discard-journal", false, "Start the journal over even if the last run left orders in it unfinished")
+	flag.StringVar(&cliGRPCAddr, "grpc-addr", "", "The address to serve the gRPC API on with the serve command, empty doesn't serve it")
+	flag.IntVar(&cliReplications, "replications", 1, "The runs of each configuration, each with the next seed, more than one reports the mean and 95% confidence interval of each statistic")
+	flag.IntVar(&cliParallel, "parallel", 1, "The runs at once when there's more than one replication or with the sweep and optimize commands")
+	flag.StringVar(&cliSweepOut, "sweep-out", "", "The file to write the sweep command's table to, empty is stdout")
+	flag.StringVar(&cliSweepFormat, "sweep-format", "csv", "The format of the sweep command's table, csv or json")
+	flag.Float64Var(&cliArrivalRate, "arrival-rate", 0, "The customers arriving an hour through the open hours, one hour when there aren't any, sets the customer count")
+	flag.IntVar(&cliSLASeconds, "sla-seconds", 300, "The p95 wait in seconds the optimize command has to meet")
+	flag.Float64Var(&costs.Barista, "barista-cost", 20, "What each barista costs for the optimize command")
+	flag.Float64Var(&costs.Grinder, "grinder-cost", 2, "What each grinder costs for the optimize command")
+	flag.Float64Var(&costs.Brewer, "brewer-cost", 3, "What each brewer costs for the optimize command")
+	flag.Float64Var(&costs.Kiosk, "kiosk-cost", 1, "What each kiosk costs for the optimize command")
+
+	// parse command line, serve runs the shop behind an HTTP API
+	// instead of sending in the customers, sweep runs the shop with
+	// every combination of the settings after the flags and optimize
+	// looks for the cheapest of them that meets the SLA.  compare runs
+	// the shop with and without the settings after the flags changed.
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && (args[0] == "serve" || args[0] == "sweep" || args[0] == "optimize" || args[0] == "compare") {
+		command = args[0]
+		args = args[1:]
+	}
+	serve := command == "serve"
+	flag.CommandLine.Parse(args)
+
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+
+	// an arrival rate is the customers spread through the day
+	if cliArrivalRate > 0 {
+		if config.OpenHours <= 0 {
+			config.OpenHours = 1
+		}
+		config.CustomerCount = int(math.Round(cliArrivalRate * config.OpenHours))
+	}
+
+	// a workload replays the same customers against every config,
+	// the day is open till the last of them unless it's set
+	if cliWorkloadFile != "" {
+		if cliArrivalRate > 0 {
+			fmt.Fprintln(os.Stderr, "-arrival-rate doesn't change a workload's customers")
+			os.Exit(1)
+		}
+		workload, err := sim.LoadWorkload(cliWorkloadFile, sim.Menu())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		config.Workload = workload
+		config.CustomerCount = len(workload)
+		openHoursSet := false
+		flag.Visit(func(f *flag.Flag) {
+			openHoursSet = openHoursSet || f.Name == "open-hours"
+		})
+		if !openHoursSet {
+			config.OpenHours = sim.WorkloadHours(workload)
+		}
+	}
+
+	// a scenario is played against a single run of the shop
+	// and that run's customers are the ones recorded
+	if (cliScenarioFile != "" || cliRecordFile != "") && (command != "" || cliReplications > 1) {
+		fmt.Fprintln(os.Stderr, "-scenario and -record are for a single run of the shop")
+		os.Exit(1)
+	}
+
+	switch command {
+	case "sweep":
+		fmt.Fprintln(os.Stderr, "Seed", config.Seed)
+		if err := sweep(&config, flag.Args(), cliReplications, cliParallel, cliSweepOut, cliSweepFormat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "optimize":
+		fmt.Fprintln(os.Stderr, "Seed", config.Seed)
+		sla := time.Duration(cliSLASeconds) * models.SimSecond
+		if err := optimize(&config, flag.Args(), costs, sla, cliReplications, cliParallel); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "compare":
+		fmt.Fprintln(os.Stderr, "Seed", config.Seed)
+		if err := compare(&config, flag.Args(), cliReplications, cliParallel); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// the scenario is read before the shop is built so a mistake in it fails fast
+	var scenario *sim.Scenario
+	if cliScenarioFile != "" {
+		loaded, err := sim.LoadScenario(cliScenarioFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		scenario = loaded
+	}
+
+	// more than one run estimates the statistics instead of
+	// showing how the shop did
+	if !serve && cliReplications > 1 {
+		fmt.Fprintln(os.Stderr, "Seed", config.Seed)
+		if err := replicate(&config, cliReplications, cliParallel); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println("Seed", config.Seed)
+
+	shopOptions := []models.ShopOption{}
+
+	// trace every order to a file or stdout
+	if cliTraceFile == "-" {
+		shopOptions = append(shopOptions, models.WithTracer(models.NewJSONExporter(os.Stdout)))
+	} else if cliTraceFile != "" {
+		traceFile, err := os.Create(cliTraceFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer traceFile.Close()
+		shopOptions = append(shopOptions, models.WithTracer(models.NewJSONExporter(traceFile)))
+	}
+
+	// the queueing comparison needs the waits at each stage
+	var stageWaits *sim.StageWaits
+	if cliQueueing {
+		stageWaits = sim.NewStageWaits()
+		shopOptions = append(shopOptions, models.WithTracer(stageWaits))
+	}
+
+	// journal the orders so a crashed run can be finished, recovering
+	// picks up the journal where it left off.  Starting over would
+	// lose the orders a crashed run left unless it's asked for.
+	recovered := []*models.Order{}
+	if cliJournalFile != "" {
+		if !cliRecover && !cliDiscardJournal {
+			if err := checkJournalFinished(cliJournalFile, config.Menu()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		if !cliRecover {
+			if err := os.Remove(cliJournalFile); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		journal, orders, err := models.OpenJournalFile(cliJournalFile, config.Menu())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer journal.Close()
+		recovered = orders
+		shopOptions = append(shopOptions, models.WithJournal(journal))
+	} else if cliRecover {
+		fmt.Fprintln(os.Stderr, "-recover needs the -journal to recover from")
+		os.Exit(1)
+	}
+
+	// the live view takes over the screen, the log
+	// would scroll it away so it's dropped
+	screen := os.Stdout
+	if cliTUI && !serve {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Stdout = devNull
 	}
 
 	// create the coffee shop with all the stuff
-	shop := models.NewCoffeeShop(menu, cliKioskCount, cliBaristaCount, cliBaristaOrderCount, grinders, brewers)
+	shop, err := sim.NewShop(config, shopOptions...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// the recovered orders go ahead of anyone new
+	if cliRecover {
+		fmt.Println("Recovered", len(recovered), "orders from the journal")
+		for _, order := range recovered {
+			shop.Requeue(order)
+		}
+	}
+
+	if serve {
+		if cliGRPCAddr != "" {
+			listener, err := net.Listen("tcp", cliGRPCAddr)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			grpcServer := grpc.NewServer()
+			rpc.RegisterCoffeeShopServer(grpcServer, rpc.New(shop, shop.Menu))
+			fmt.Println("Serving gRPC on", cliGRPCAddr)
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+			}()
+		}
+
+		fmt.Println("Serving the coffee shop on", cliAddr)
+		if err := http.ListenAndServe(cliAddr, server.New(shop, shop.Menu)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// a long simulation can be scraped while it runs, serve
+	// has the metrics with the rest of the API
+	if cliMetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", server.MetricsHandler(shop))
+		fmt.Println("Serving metrics on", cliMetricsAddr)
+		go func() {
+			if err := http.ListenAndServe(cliMetricsAddr, mux); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
+	// the customers are recorded before the scenario adds its bursts
+	// so replaying them with the same scenario gets the same day
+	if cliRecordFile != "" {
+		if err := sim.SaveWorkload(cliRecordFile, shop.Arrivals); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	var playback *sim.Playback
+	if scenario != nil {
+		playback, err = scenario.Play(shop)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	var dashboard *tui.Dashboard
+	if cliTUI {
+		dashboard = tui.New(shop, screen)
+		dashboard.Start()
+	}
 
-	orderWaitGroup := sync.WaitGroup{}
-	orderWaitGroup.Add(cliCustomerCount)
 	start := time.Now()
-	for i := 0; i < cliCustomerCount; i++ {
-		// in parallel, all at once, make calls to MakeCoffee
-		go func(customer string) {
-			// model the customer
-			// wait for turn to order
-			// order random coffee off menu
-			// leave the kiosk for the next person
-			kiosk := shop.WaitForOrderingKiosk()
-			order := kiosk.CreateOrder(customer, menu[rand.Intn(len(menu))])
-			shop.LeaveOrderingKiosk(kiosk)
-
-			order.Wait()
-			fmt.Println(customer + " says Thank You")
-			orderWaitGroup.Done()
-		}(fmt.Sprintf("Customer-%d", i))
-	}
-
-	fmt.Println("Waiting for all customers to order...")
-
-	// wait for orders to be put in before we close the
-	// shop
-	orderWaitGroup.Wait()
-	fmt.Println("Customers have all ordered.")
+	shop.SendCustomers()
+	for _, order := range recovered {
+		order.Wait()
+	}
 
 	// stop taking orders and wait for baristas to finish
 	shop.Close()
 	fmt.Println("All orders complete.")
 	runTime := time.Since(start)
-	fmt.Println("Run time", runTime)
-	fmt.Println("Avg Coffee time", runTime.Milliseconds()/int64(cliCustomerCount))
+	summary := fmt.Sprintln("Run time", runTime)
+	if orders := len(shop.Arrivals) + len(recovered); orders > 0 {
+		summary += fmt.Sprintln("Avg Coffee time", runTime.Milliseconds()/int64(orders))
+	}
+	summary += shop.Stats().String()
+	if stageWaits != nil {
+		comparisons, err := shop.CompareQueues(stageWaits)
+		if err != nil {
+			summary += fmt.Sprintln("No queueing comparison:", err)
+		} else {
+			table := &strings.Builder{}
+			sim.WriteQueueComparisons(table, comparisons)
+			summary += table.String()
+		}
+	}
+	passed := true
+	if scenario != nil {
+		result := sim.Result{Config: shop.Config, Stats: shop.Stats(), RunTime: runTime}
+		failures := append(playback.Stop(), scenario.Check(result)...)
+		passed = len(failures) == 0
+		for _, failure := range failures {
+			summary += fmt.Sprintln("Scenario", failure)
+		}
+		if passed {
+			summary += fmt.Sprintln("Scenario PASS")
+		} else {
+			summary += fmt.Sprintln("Scenario FAIL")
+		}
+	}
+
+	// the summary goes under the last view of the shop
+	if dashboard != nil {
+		dashboard.Stop(summary)
+	} else {
+		fmt.Print(summary)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// checkJournalFinished is an error if the journal has orders the
+// last run didn't finish, or can't be read to tell
+func checkJournalFinished(path string, menu models.Menu) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	orders, err := models.RecoverOrders(file, menu)
+	if err != nil {
+		return fmt.Errorf("%s: %w, -discard-journal starts it over", path, err)
+	}
+	if len(orders) > 0 {
+		return fmt.Errorf("%s has %d unfinished orders, -recover finishes them or -discard-journal starts it over", path, len(orders))
+	}
+	return nil
 }
 
 // Premise: we want to model a coffee shop. An order comes in, and then with a limited amount of grinders and
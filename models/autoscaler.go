@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// how often the autoscaler looks at the shop if the policy doesn't say
+const defaultAutoscaleCheck = 30 * SimSecond
+
+// AutoscalePolicy adds baristas when orders back up and
+// sends them home when it's quiet
+type AutoscalePolicy struct {
+	// CheckEvery is how often the queue and wait are checked
+	CheckEvery  time.Duration
+	MinBaristas int
+	MaxBaristas int
+	// a barista is added when more orders than MaxQueue are in the shop
+	// waiting on coffee, or the orders served since the last check waited
+	// longer than MaxWait on average.  One is sent home when the queue and
+	// wait are both under half of those.
+	MaxQueue int
+	MaxWait  time.Duration
+	// Profile of the baristas added, they get made up names
+	Profile BaristaProfile
+}
+
+// WithAutoscaler changes the baristas working to keep up with the orders
+func WithAutoscaler(policy AutoscalePolicy) ShopOption {
+	return func(cs *coffeeShop) {
+		if policy.CheckEvery <= 0 {
+			policy.CheckEvery = defaultAutoscaleCheck
+		}
+		policy.Profile.Name = ""
+		cs.autoscale = &policy
+	}
+}
+
+func (cs *coffeeShop) autoscaler(policy AutoscalePolicy) {
+	ticker := time.NewTicker(policy.CheckEvery)
+	defer ticker.Stop()
+
+	last := cs.stats.snapshot()
+	for {
+		select {
+		case <-cs.roster.closed:
+			return
+		case <-ticker.C:
+		}
+
+		current := cs.stats.snapshot()
+		recentWait := waitBetween(last, current)
+		last = current
+
+		queue := len(cs.orders) + cs.roster.orderCount()
+		staff := cs.Baristas()
+		backedUp := queue > policy.MaxQueue || (policy.MaxWait > 0 && recentWait > policy.MaxWait)
+		quiet := queue <= policy.MaxQueue/2 && recentWait <= policy.MaxWait/2
+
+		switch {
+		case backedUp && len(staff) < policy.MaxBaristas:
+			if name := cs.AddBarista(policy.Profile); name != "" {
+				fmt.Println("Autoscaler added", name, "with", queue, "orders waiting")
+				cs.stats.baristasScaled(1)
+			}
+		case quiet && len(staff) > policy.MinBaristas:
+			// the last one hired goes home first
+			name := staff[len(staff)-1]
+			if cs.RemoveBarista(name) {
+				fmt.Println("Autoscaler sent", name, "home")
+				cs.stats.baristasScaled(-1)
+			}
+		}
+	}
+}
+
+// waitBetween is the average wait of the orders served between two snapshots
+func waitBetween(before Stats, after Stats) time.Duration {
+	served := after.OrdersServed - before.OrdersServed
+	if served <= 0 {
+		return 0
+	}
+
+	return (after.TotalWait - before.TotalWait) / time.Duration(served)
+}
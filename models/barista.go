@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 type OrderStepsChannel chan OrderEvent
@@ -24,6 +25,16 @@ type barista struct {
 	brewers      BrewerPool
 	orderCount   int
 	countLock    *sync.Mutex
+	carafes      *carafeStation
+	stats        *statsRecorder
+	profile      BaristaProfile
+	rand         lockedRand
+	shift        Shift
+	roster       *roster
+	leaving      chan struct{}
+	// breaks taken on top of the shift's
+	breaks chan Break
+	events *eventBus
 }
 
 func newBarista(name string, maxActiveOrders int, newOrders OrderChannel, g GrinderPool, b BrewerPool) *barista {
@@ -35,9 +46,38 @@ func newBarista(name string, maxActiveOrders int, newOrders OrderChannel, g Grin
 		brewers:      b,
 		orderCount:   0,
 		countLock:    &sync.Mutex{},
+		stats:        newStatsRecorder(),
+		profile:      DefaultBaristaProfile(name),
+		rand:         newLockedRand(time.Now().UnixNano()),
+		roster:       newRoster(time.Now()),
+		leaving:      make(chan struct{}),
+		breaks:       make(chan Break, 1),
 	}
 }
 
+// takeBreak adds a break to the barista's shift, it's false
+// if they haven't started on the last one asked for
+func (b *barista) takeBreak(br Break) bool {
+	select {
+	case b.breaks <- br:
+		fmt.Println(b.Name, "is going on break")
+		return true
+	default:
+		return false
+	}
+}
+
+// leave has the barista stop taking new orders and go
+// home once the ones they have are done
+func (b *barista) leave() {
+	close(b.leaving)
+}
+
+// slipsUp is true if the barista gets a step at the station wrong
+func (b *barista) slipsUp(station Station) bool {
+	return b.rand.float64() < b.profile.errorRateAt(station)
+}
+
 // Keep an internal count of active orders to help
 // the barista know when it's done
 func (b *barista) incrementOrderCount() {
@@ -61,51 +101,197 @@ func (b *barista) getCurrentOrderCount() int {
 	return b.orderCount
 }
 
-// ServeCustomers reads the new orders channel to start new orders
-// or reads current orders channel to progress existing orders.
-// If a stop is requested (by closing the shop and new orders channel)
-// focus on the existing orders till they're done.
+// ServeCustomers works the barista's shift.  While clocked in they read
+// the new orders channel to start new orders or the current orders channel
+// to progress existing orders.  Off the clock the next step of anything they
+// had going is handed off to someone working.  If a stop is requested (by
+// closing the shop and new orders channel or the barista leaving) focus on
+// the existing orders till they're done.
 func (b *barista) ServeCustomers() {
-	for stopping := false; !stopping || b.getCurrentOrderCount() > 0; {
+	b.roster.join(b)
+	clockedIn := false
+	for stopping := false; ; {
+		working, change := b.shift.at(b.roster.sinceOpen())
+		if working && !clockedIn && !stopping {
+			b.roster.clockIn(b)
+			clockedIn = true
+		}
+
+		if clockedIn && stopping && b.getCurrentOrderCount() == 0 {
+			if b.roster.clockOut(b, true) {
+				break
+			}
+			// someone off the clock still has orders to hand over
+			change = b.roster.sinceOpen() + overtimeCheck
+		}
+		if !clockedIn && (stopping || b.roster.isClosed()) && b.getCurrentOrderCount() == 0 {
+			break
+		}
+
+		if !working && clockedIn {
+			if b.roster.clockOut(b, false) {
+				clockedIn = false
+			} else {
+				// nobody to take over, work overtime and check back
+				change = b.roster.sinceOpen() + overtimeCheck
+			}
+		}
+
+		// only take new orders on the clock, and only wait for
+		// closing when off it
+		var newOrders OrderChannel
+		var closing chan struct{}
+		var leaving chan struct{}
+		if clockedIn && !stopping {
+			newOrders = b.newOrders
+		}
 		if !stopping {
+			leaving = b.leaving
+		}
+		if !clockedIn && !b.roster.isClosed() {
+			closing = b.roster.closed
+		}
+		var shiftChange <-chan time.Time
+		var timer *time.Timer
+		if change > 0 {
+			timer = time.NewTimer(change - b.roster.sinceOpen())
+			shiftChange = timer.C
+		}
+
+		// the next step of an order underway goes ahead of anything
+		// else, a select picks at random when more than one is ready
+		select {
+		case existingOrderEvent := <-b.activeOrders:
+			b.nextStep(existingOrderEvent, clockedIn)
+		default:
 			select {
 			case existingOrderEvent := <-b.activeOrders:
-				b.progressOrder(existingOrderEvent)
-			case newOrder, isOpen := <-b.newOrders:
+				b.nextStep(existingOrderEvent, clockedIn)
+			case newOrder, isOpen := <-newOrders:
 				if newOrder == nil && !isOpen {
 					stopping = true
 				} else {
 					b.startOrder(newOrder)
 				}
-			}
-		} else {
-			// we're stopping, only read from existing orders
-			// and only if order count is > 0
-			if b.getCurrentOrderCount() > 0 {
-				existingOrderEvent := <-b.activeOrders
-				b.progressOrder(existingOrderEvent)
+			case <-leaving:
+				fmt.Println(b.Name, "is finishing up to leave")
+				stopping = true
+			case br := <-b.breaks:
+				b.shift.Breaks = append(b.shift.Breaks, br)
+			case <-shiftChange:
+			case <-closing:
 			}
 		}
+
+		if timer != nil {
+			timer.Stop()
+		}
 	}
 
 	fmt.Println(b.Name, "is done for the day")
 }
 
+// nextStep progresses the order on the clock,
+// off it the order's handed off to someone working
+func (b *barista) nextStep(event OrderEvent, clockedIn bool) {
+	if clockedIn {
+		b.progressOrder(event)
+	} else {
+		b.roster.handOff(b, event)
+	}
+}
+
 func (b *barista) startOrder(newOrder *Order) {
+	if newOrder.isCancelled() {
+		fmt.Println(b.Name, "skipped the cancelled order from", newOrder.Customer)
+		b.stats.orderCancelled()
+		return
+	}
+
 	fmt.Println(b.Name, "is working on order from", newOrder.Customer)
+	newOrder.workedBy(b.Name)
+	if !newOrder.isBatch() && b.carafes.serves(newOrder.Item) {
+		b.startCarafeOrder(newOrder)
+		return
+	}
+
+	// a recovered order with its beans ground goes on to a brewer
+	if newOrder.GetStatus() == ReadyToBrew {
+		b.incrementOrderCount()
+		b.requestBrewer(NewGrindCompleteEvent(newOrder, newOrder.GroundBeans))
+		return
+	}
+
 	// new orders need to be ground, set the status to ReadyToGrind
 	// request a grinder and move on till it's available
-	newOrder.Status = ReadyToGrind
+	newOrder.setStatus(ReadyToGrind)
 	b.incrementOrderCount()
+	wait := newOrder.trace.start(GrinderWaitSpan, "barista", b.Name)
 	go func() {
-		grinder := b.grinders.GetGrinder()
+		grinder := b.grinders.GetGrinderFor(newOrder.Item.Variety)
+		wait.end("machine", b.grinders.Name(grinder))
 		fmt.Println(b.Name, "got grinder for", newOrder.Customer)
+		b.poolChanged()
 		// notfiy the barista the grinder is available
 		b.activeOrders <- NewGrinderAvailableEvent(newOrder, grinder)
 	}()
 }
 
+// startCarafeOrder pours from a carafe or waits on a batch,
+// starting one if nobody is brewing it yet
+func (b *barista) startCarafeOrder(newOrder *Order) {
+	newOrder.serving = newOrder.trace.start(ServeSpan, "barista", b.Name, "machine", "carafe")
+	coffee, batchCups := b.carafes.takeOrder(newOrder)
+	if coffee != nil {
+		fmt.Println(b.Name, "poured from the carafe for", newOrder.Customer)
+		b.notifyCustomer(newOrder, coffee)
+		return
+	}
+
+	fmt.Println(newOrder.Customer, "is waiting on a carafe of", newOrder.Item.Name)
+	if batchCups > 0 {
+		b.startBatch(newOrder.Item, batchCups)
+	}
+}
+
+// startBatch brews a batch to fill a carafe
+func (b *barista) startBatch(item MenuItem, cups int) {
+	batch := newBatchOrder(item, cups)
+	batch.events = b.events
+	b.startOrder(batch)
+}
+
+// poolChanged publishes what's free after the barista
+// takes or puts back a grinder or brewer
+func (b *barista) poolChanged() {
+	if b.events == nil {
+		return
+	}
+
+	b.events.publish(ShopEvent{
+		Kind:            PoolEvent,
+		Barista:         b.Name,
+		GrindersFree:    b.grinders.Available(),
+		BrewerSlotsFree: b.brewers.Available(),
+	})
+}
+
 func (b *barista) progressOrder(event OrderEvent) {
+	order := event.GetOrder()
+	b.events.publish(ShopEvent{
+		Kind:     StepEvent,
+		OrderID:  order.ID,
+		Customer: order.Customer,
+		Status:   order.GetStatus(),
+		Step:     stepName(event),
+		Barista:  b.Name,
+	})
+
+	if order.isCancelled() {
+		b.dropOrder(event)
+		return
+	}
+
 	switch {
 	case isGrinderAvailable(event):
 		b.grindCoffee(event.(GrinderAvailableEvent))
@@ -121,19 +307,47 @@ func (b *barista) progressOrder(event OrderEvent) {
 	}
 }
 
+// dropOrder stops work on a cancelled order and
+// puts back whatever it was holding
+func (b *barista) dropOrder(event OrderEvent) {
+	b.decrementOrderCount()
+	order := event.GetOrder()
+	fmt.Println(b.Name, "dropped the cancelled order from", order.Customer)
+	b.stats.orderCancelled()
+
+	switch e := event.(type) {
+	case GrinderAvailableEvent:
+		b.grinders.AddGrinder(e.GetGrinder())
+	case BrewerAvailableEvent:
+		b.brewers.ReturnBrewer(e.GetBrewer())
+	}
+	b.poolChanged()
+}
+
 func (b *barista) grindCoffee(ge GrinderAvailableEvent) {
+	grind := ge.GetOrder().trace.start(GrindSpan,
+		"barista", b.Name, "machine", b.grinders.Name(ge.GetGrinder()))
 	go func() {
 		order := ge.GetOrder()
-		order.Status = Grinding
+		order.setStatus(Grinding)
 		grinder := ge.GetGrinder()
 
-		// grind the right amount of beans for the order
-		ungroundBeans := Beans{weightGrams: order.Item.CoffeeRatio * order.Item.Size}
+		// weigh out the right amount of beans for the order
+		time.Sleep(b.profile.handsOnTime(doseSeconds, GrindStation))
+		ungroundBeans := Beans{
+			weight:  order.Item.CoffeeRatio.Coffee(order.volume()),
+			variety: order.Item.Variety,
+		}
+		if b.slipsUp(GrindStation) {
+			ungroundBeans.weight *= 0.7
+		}
 
 		// save the ground beans
 		fmt.Println(b.Name, "is grinding coffee for", order.Customer)
 		beans := grinder.Grind(ungroundBeans)
+		grind.end()
 		b.grinders.AddGrinder(grinder)
+		b.poolChanged()
 
 		b.activeOrders <- NewGrindCompleteEvent(order, beans)
 	}()
@@ -141,27 +355,53 @@ func (b *barista) grindCoffee(ge GrinderAvailableEvent) {
 
 func (b *barista) requestBrewer(ge GrindCompleteEvent) {
 	order := ge.GetOrder()
-	order.Status = ReadyToBrew
 	order.GroundBeans = ge.GetBeans()
+	order.groundAt = ge.GetGroundAt()
+	order.setStatus(ReadyToBrew)
 	fmt.Println(b.Name, "is getting a brewer for", order.Customer)
+	wait := order.trace.start(BrewerWaitSpan, "barista", b.Name)
 	go func() {
-		brewer := b.brewers.GetBrewer()
+		var brewer Brewer
+		if order.isBatch() {
+			brewer = b.brewers.GetBatchBrewer()
+		} else {
+			brewer = b.brewers.GetBrewerFor(b.profile.trainedItem(order.Item))
+		}
+		wait.end("machine", b.brewers.Name(brewer))
 		fmt.Println(b.Name, "got a brewer for", order.Customer)
+		b.poolChanged()
 		b.activeOrders <- NewBrewerAvailableEvent(order, brewer)
 	}()
 }
 
 func (b *barista) brewCoffee(ge BrewerAvailableEvent) {
+	brew := ge.GetOrder().trace.start(BrewSpan,
+		"barista", b.Name, "machine", b.brewers.Name(ge.GetBrewer()))
 	go func() {
 		order := ge.GetOrder()
-		order.Status = Brewing
+		order.setStatus(Brewing)
 		brewer := ge.GetBrewer()
-		fmt.Println(b.Name, "is brewing coffee for", order.Customer)
+		fmt.Println(b.Name, "is brewing", brewer.Method(), "coffee for", order.Customer)
 
 		// brew the coffee to the final volume
-		coffee := brewer.Brew(order.Item.Size, order.GroundBeans)
+		station := stationFor(brewer.Method())
+		volume := order.volume()
+		if b.slipsUp(station) {
+			volume *= 0.75
+		}
+		groundsAge := time.Since(order.groundAt)
+		start := time.Now()
+		coffee := brewer.Brew(volume, order.GroundBeans)
+		coffee.item = order.Item
+		coffee.quality = newQuality(groundsAge, time.Since(start), coffee.ratio)
 		fmt.Println(b.Name, "is done brewing coffee for", order.Customer)
-		b.brewers.AddBrewer(brewer)
+		brew.end()
+		b.brewers.ReturnBrewer(brewer)
+		b.poolChanged()
+		order.serving = order.trace.start(ServeSpan, "barista", b.Name)
+
+		// pour it for the customer
+		time.Sleep(b.profile.handsOnTime(pourSeconds, station))
 
 		b.activeOrders <- NewCoffeeCompleteEvent(order, coffee)
 	}()
@@ -170,9 +410,56 @@ func (b *barista) brewCoffee(ge BrewerAvailableEvent) {
 func (b *barista) serveCoffee(cc CoffeeCompleteEvent) {
 	b.decrementOrderCount()
 	order := cc.GetOrder()
-	order.Status = Complete
 	coffee := cc.GetCoffee()
 
+	// check it's the right drink before it goes out
+	if !passesQA(order, coffee) {
+		order.serving.end("remade", "true")
+		b.remakeOrder(order, coffee)
+		return
+	}
+	if order.isBatch() {
+		order.setStatus(Complete)
+		b.fillCarafe(order, coffee)
+		return
+	}
+
+	b.notifyCustomer(order, coffee)
+}
+
+// remakeOrder throws out the wrong drink and starts the order over
+func (b *barista) remakeOrder(order *Order, coffee *Coffee) {
+	fmt.Println(b.Name, "caught a mistake and is remaking the order for", order.Customer)
+	b.stats.remade(order, coffee)
+	if order.remadeAt.IsZero() {
+		order.remadeAt = time.Now()
+	}
+	order.setStatus(Ordered)
+	b.startOrder(order)
+}
+
+// fillCarafe pours the new batch for everyone waiting on it
+func (b *barista) fillCarafe(batch *Order, coffee *Coffee) {
+	fmt.Println(b.Name, "filled a", batch.Customer)
+	b.stats.batchBrewed()
+	served, poured, nextBatchCups := b.carafes.fill(batch.Item, coffee, batch.batchCups)
+	for i, order := range served {
+		b.notifyCustomer(order, poured[i])
+	}
+
+	if nextBatchCups > 0 {
+		b.startBatch(batch.Item, nextBatchCups)
+	}
+}
+
+// notifyCustomer hands over the coffee, an order cancelled
+// while it was poured is thrown out
+func (b *barista) notifyCustomer(order *Order, coffee *Coffee) {
+	order.stats = b.stats
+	if !order.NotifyCustomer(coffee) {
+		fmt.Println(b.Name, "threw out the coffee for the cancelled order from", order.Customer)
+		b.stats.orderCancelled()
+		return
+	}
 	fmt.Println(b.Name, "says coffee is ready for", order.Customer)
-	order.NotifyCustomer(coffee)
 }
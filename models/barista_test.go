@@ -1,6 +1,7 @@
 package models
 
 import (
+	"blreynolds4/coffeeshop/units"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,9 +56,12 @@ func TestProgressToGrind(t *testing.T) {
 	assert.Equal(t, Grinding, order.Status)
 	assert.Equal(t, order.Status, oe.GetOrder().Status)
 
-	// make sure it's the grinder available event
-	_, ok := oe.(GrindCompleteEvent)
+	// make sure it's the grind complete event with
+	// enough beans for the size at the ratio
+	gce, ok := oe.(GrindCompleteEvent)
 	assert.True(t, ok)
+	assert.Equal(t, units.Grams(15), gce.GetBeans().weight)
+	assert.Equal(t, order.Item.Variety, gce.GetBeans().variety)
 }
 
 // Grinding to ReadyToBrew
@@ -68,7 +72,7 @@ func TestProgressToGetBrewer(t *testing.T) {
 
 	order := NewOrder("customer", getTestMenuItem())
 
-	barista.progressOrder(NewGrindCompleteEvent(order, Beans{weightGrams: 5}))
+	barista.progressOrder(NewGrindCompleteEvent(order, Beans{weight: 5}))
 
 	// make sure the brewer available was sent and order is right
 	oe, sent := <-barista.activeOrders
@@ -117,7 +121,8 @@ func TestProgressToComplete(t *testing.T) {
 
 	order := NewOrder("customer", getTestMenuItem())
 
-	expectedCoffee := &Coffee{sizeOunces: order.Item.Size}
+	beans := NewBeans(order.Item.CoffeeRatio.Coffee(order.Item.Size), order.Item.Variety)
+	expectedCoffee := NewCoffee(order.Item.Size, beans, &MockBrewer{})
 
 	barista.progressOrder(
 		NewCoffeeCompleteEvent(order, expectedCoffee))
@@ -127,6 +132,34 @@ func TestProgressToComplete(t *testing.T) {
 	assert.Equal(t, expectedCoffee, freshCoffee)
 }
 
+// A drink that fails QA is thrown out and the order starts over
+func TestProgressToRemake(t *testing.T) {
+	bName := "test"
+	orderChan := make(OrderChannel)
+	barista := newBarista(bName, 1, orderChan, getTestGrinders(), getTestBrewers())
+
+	order := NewOrder("customer", getTestMenuItem())
+
+	// brewed short, it's not the drink on the order
+	beans := NewBeans(order.Item.CoffeeRatio.Coffee(order.Item.Size), order.Item.Variety)
+	wrongCoffee := NewCoffee(order.Item.Size/2, beans, &MockBrewer{})
+
+	barista.progressOrder(
+		NewCoffeeCompleteEvent(order, wrongCoffee))
+
+	// the order goes back to the grinder
+	oe, sent := <-barista.activeOrders
+	assert.True(t, sent)
+	_, ok := oe.(GrinderAvailableEvent)
+	assert.True(t, ok)
+	assert.Equal(t, ReadyToGrind, order.Status)
+	assert.False(t, order.remadeAt.IsZero())
+
+	stats := barista.stats.snapshot()
+	assert.Equal(t, 1, stats.Remakes)
+	assert.Equal(t, order.Item.Size/2, stats.RemakeWastedCoffee)
+}
+
 // End to End with ServeCustomer
 func TestServeCustomerFullOrder(t *testing.T) {
 	bName := "test"
@@ -135,16 +168,40 @@ func TestServeCustomerFullOrder(t *testing.T) {
 
 	order := NewOrder("customer", getTestMenuItem())
 
-	expectedCoffee := &Coffee{sizeOunces: order.Item.Size}
-
 	// start the barista
 	go barista.ServeCustomers()
 
 	// put in the order
 	orderChan <- order
 
-	// verify order Wait returns the coffee
+	// verify order Wait returns the coffee made for the order
 	freshCoffee := order.Wait()
 
-	assert.Equal(t, expectedCoffee, freshCoffee)
+	assert.Equal(t, order.Item.Size, freshCoffee.Volume())
+	assert.Equal(t, order.Item, freshCoffee.Item())
+	assert.Equal(t, order.Item.CoffeeRatio, freshCoffee.Ratio())
+	assert.Equal(t, order.Item.Variety, freshCoffee.Variety())
+	assert.False(t, freshCoffee.BrewedAt().IsZero())
+}
+
+// A cancelled order is dropped and the brewer slot put back
+func TestProgressCancelledOrder(t *testing.T) {
+	bName := "test"
+	orderChan := make(OrderChannel)
+	barista := newBarista(bName, 1, orderChan, getTestGrinders(), NewBrewerPool())
+
+	order := NewOrder("customer", getTestMenuItem())
+	barista.incrementOrderCount()
+	order.Cancel()
+
+	brewer := &MockBrewer{}
+	barista.progressOrder(NewBrewerAvailableEvent(order, brewer))
+
+	assert.Equal(t, 0, barista.getCurrentOrderCount())
+	assert.Equal(t, Brewer(brewer), barista.brewers.GetBrewer())
+	assert.Equal(t, 1, barista.stats.snapshot().Cancelled)
+
+	// a cancelled new order isn't started
+	barista.startOrder(order)
+	assert.Equal(t, 0, barista.getCurrentOrderCount())
 }
@@ -1,32 +1,246 @@
 package models
 
 import (
+	"blreynolds4/coffeeshop/units"
 	"fmt"
 	"time"
 )
 
+type BrewMethod int
+
+const (
+	Drip BrewMethod = iota
+	PourOver
+	FrenchPress
+	AeroPress
+	BatchDrip
+)
+
+func (m BrewMethod) String() string {
+	switch m {
+	case Drip:
+		return "Drip"
+	case PourOver:
+		return "Pour Over"
+	case FrenchPress:
+		return "French Press"
+	case AeroPress:
+		return "AeroPress"
+	case BatchDrip:
+		return "Batch Drip"
+	}
+
+	return "unknown brew method"
+}
+
+// Body is how heavy the coffee feels, paper filters
+// make a cleaner cup than metal screens
+func (m BrewMethod) Body() Body {
+	switch m {
+	case PourOver:
+		return LightBody
+	case FrenchPress:
+		return FullBody
+	}
+
+	return MediumBody
+}
+
+type Body int
+
+const (
+	LightBody Body = iota
+	MediumBody
+	FullBody
+)
+
+func (b Body) String() string {
+	switch b {
+	case LightBody:
+		return "Light"
+	case MediumBody:
+		return "Medium"
+	case FullBody:
+		return "Full"
+	}
+
+	return "unknown body"
+}
+
 type Brewer interface {
-	Brew(finishedVolume int, beans Beans) *Coffee
+	Brew(finishedVolume units.Milliliters, beans Beans) *Coffee
+	Method() BrewMethod
+	// Cups is how many cups the brewer can make at once
+	Cups() int
 }
 
+// brewerBase has what every brewing method shares
+type brewerBase struct {
+	cups   int
+	timing DurationModel
+	warmer
+}
+
+type BrewerOption func(*brewerBase)
+
+// WithCups sets how many cups the brewer can make at once,
+// the brewer pool leases a slot for each cup
+func WithCups(cups int) BrewerOption {
+	return func(b *brewerBase) {
+		b.cups = cups
+	}
+}
+
+// WithBrewModel replaces the method's own brew time
+func WithBrewModel(timing DurationModel) BrewerOption {
+	return func(b *brewerBase) {
+		b.timing = timing
+	}
+}
+
+// WithBrewerWarmUp makes a cold brewer take extra time
+func WithBrewerWarmUp(warmUp WarmUp) BrewerOption {
+	return func(b *brewerBase) {
+		b.warmUp = warmUp
+	}
+}
+
+func newBrewerBase(cups int, timing DurationModel, opts []BrewerOption) brewerBase {
+	result := brewerBase{
+		cups:   cups,
+		timing: timing,
+		warmer: newWarmer(),
+	}
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+func (b *brewerBase) Cups() int {
+	return b.cups
+}
+
+// brew for the brewer embedding the base
+func (b *brewerBase) brew(brewer Brewer, finishedVolume units.Milliliters, beans Beans) *Coffee {
+	method := brewer.Method()
+	if warmUp := b.start(); warmUp > 0 {
+		fmt.Printf("Warming up %s brewer for %.1f Seconds\n", method, SimSeconds(warmUp))
+		time.Sleep(warmUp)
+	}
+	defer b.done()
+
+	brewTime := b.timing.Duration(float64(finishedVolume))
+	fmt.Printf("%s brewing %s of coffee into %s for %.1f Seconds\n",
+		method, beans.weight, finishedVolume, SimSeconds(brewTime))
+	time.Sleep(brewTime)
+	fmt.Println("Brew Complete")
+	return NewCoffee(finishedVolume, beans, brewer)
+}
+
+// brewer is a drip machine
 type brewer struct {
-	// assume we have unlimited water, but we can only run a
-	// certain amount of water per second into our brewer + beans
-	ouncesWaterPerSecond int
+	brewerBase
 }
 
-func NewBrewer(ouncesWaterPerSecond int) Brewer {
+// NewBrewer creates a drip brewer.  We assume we have unlimited water,
+// but we can only run a certain amount of water per second into our
+// brewer + beans.
+func NewBrewer(waterPerSecond units.MillilitersPerSecond, opts ...BrewerOption) Brewer {
 	return &brewer{
-		ouncesWaterPerSecond: ouncesWaterPerSecond,
+		brewerBase: newBrewerBase(4, NewRateDuration(float64(waterPerSecond)), opts),
 	}
 }
 
+func (b *brewer) Method() BrewMethod {
+	return Drip
+}
+
 // take the right amount of water and the beans and brew the coffee
-func (b *brewer) Brew(finishedVolume int, beans Beans) *Coffee {
-	// do the brewing
-	brewTime := b.ouncesWaterPerSecond * finishedVolume
-	fmt.Printf("Brewing %d grams for %d Seconds\n", beans.weightGrams, brewTime)
-	time.Sleep(time.Duration(brewTime) * time.Millisecond)
-	fmt.Println("Brew Complete")
-	return &Coffee{sizeOunces: finishedVolume}
+func (b *brewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return b.brew(b, finishedVolume, beans)
+}
+
+// pour over wets the grounds and lets them bloom
+// before slowly pouring the rest of the water
+type pourOverBrewer struct {
+	brewerBase
+}
+
+func NewPourOverBrewer(bloomSeconds int, waterPerSecond units.MillilitersPerSecond, opts ...BrewerOption) Brewer {
+	bloom := time.Duration(bloomSeconds) * SimSecond
+	return &pourOverBrewer{
+		brewerBase: newBrewerBase(1, NewFixedPlusRateDuration(bloom, float64(waterPerSecond)), opts),
+	}
+}
+
+func (b *pourOverBrewer) Method() BrewMethod {
+	return PourOver
+}
+
+func (b *pourOverBrewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return b.brew(b, finishedVolume, beans)
+}
+
+// french press steeps the grounds for the same time
+// no matter how much is in the pot
+type frenchPressBrewer struct {
+	brewerBase
+}
+
+func NewFrenchPressBrewer(steepSeconds int, opts ...BrewerOption) Brewer {
+	steep := time.Duration(steepSeconds) * SimSecond
+	return &frenchPressBrewer{
+		brewerBase: newBrewerBase(4, NewFixedDuration(steep), opts),
+	}
+}
+
+func (b *frenchPressBrewer) Method() BrewMethod {
+	return FrenchPress
+}
+
+func (b *frenchPressBrewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return b.brew(b, finishedVolume, beans)
+}
+
+// aeropress is a short steep then a fixed time
+// to push the water through
+type aeroPressBrewer struct {
+	brewerBase
+}
+
+func NewAeroPressBrewer(steepSeconds int, plungeSeconds int, opts ...BrewerOption) Brewer {
+	steepAndPlunge := time.Duration(steepSeconds+plungeSeconds) * SimSecond
+	return &aeroPressBrewer{
+		brewerBase: newBrewerBase(1, NewFixedDuration(steepAndPlunge), opts),
+	}
+}
+
+func (b *aeroPressBrewer) Method() BrewMethod {
+	return AeroPress
+}
+
+func (b *aeroPressBrewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return b.brew(b, finishedVolume, beans)
+}
+
+// batch brewer fills a carafe with many cups at once
+// so orders can be poured while the carafe is fresh
+type batchBrewer struct {
+	brewerBase
+}
+
+func NewBatchBrewer(waterPerSecond units.MillilitersPerSecond, opts ...BrewerOption) Brewer {
+	return &batchBrewer{
+		brewerBase: newBrewerBase(1, NewRateDuration(float64(waterPerSecond)), opts),
+	}
+}
+
+func (b *batchBrewer) Method() BrewMethod {
+	return BatchDrip
+}
+
+func (b *batchBrewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return b.brew(b, finishedVolume, beans)
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,3 +28,81 @@ func TestWaitForBrewer(t *testing.T) {
 
 	assert.Equal(t, b1, readBrewer)
 }
+
+func TestWaitForCompatibleBrewer(t *testing.T) {
+	drip := NewBrewer(2)
+	press := NewFrenchPressBrewer(2)
+	bp := NewBrewerPool(drip)
+
+	go func() {
+		bp.AddBrewer(press)
+	}()
+
+	item := getTestMenuItem()
+	item.Methods = []BrewMethod{FrenchPress}
+
+	assert.Equal(t, press, bp.GetBrewerFor(item))
+}
+
+func TestBrewerSlots(t *testing.T) {
+	b1 := NewBrewer(2, WithCups(2))
+	bp := NewBrewerPool(b1)
+
+	// both slots can be leased at once
+	assert.Equal(t, b1, bp.GetBrewer())
+	assert.Equal(t, b1, bp.GetBrewer())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		bp.ReturnBrewer(b1)
+	}()
+
+	// the third waits for a slot to come back
+	assert.Equal(t, b1, bp.GetBrewer())
+
+	usage := bp.Utilization()
+	assert.Len(t, usage, 2)
+	assert.Equal(t, "Drip-0", usage[0].Brewer)
+	assert.Equal(t, 0, usage[0].Slot)
+	assert.Equal(t, 1, usage[1].Slot)
+	for _, slot := range usage {
+		assert.GreaterOrEqual(t, slot.Busy.Milliseconds(), int64(10))
+	}
+}
+
+func TestRemoveBrewer(t *testing.T) {
+	drip := NewBrewer(2, WithCups(2))
+	press := NewFrenchPressBrewer(2)
+	bp := NewBrewerPool(drip, press)
+
+	// lease a drip slot then take the machine out
+	leased := bp.GetBrewerFor(MenuItem{Methods: []BrewMethod{Drip}})
+	assert.Equal(t, drip, leased)
+	bp.RemoveBrewer(drip)
+
+	// the returned slot doesn't go back in the pool,
+	// only the press slots are left
+	bp.ReturnBrewer(leased)
+	items := bp.(*brewerPool).items
+	assert.Len(t, items, press.Cups())
+	assert.NotContains(t, items, drip)
+
+	// the drip usage stays in the report
+	assert.Len(t, bp.Utilization(), 6)
+}
+
+func TestRestoreBrewer(t *testing.T) {
+	drip := NewBrewer(2, WithCups(2))
+	bp := NewBrewerPool(drip)
+	assert.Equal(t, drip, bp.Machine("Drip-0"))
+	assert.Nil(t, bp.Machine("Drip-1"))
+
+	// fixed while a slot was still in use
+	leased := bp.GetBrewer()
+	bp.RemoveBrewer(drip)
+	bp.RestoreBrewer(drip)
+	assert.Equal(t, 1, bp.Available())
+	bp.ReturnBrewer(leased)
+	assert.Equal(t, 2, bp.Available())
+	assert.Len(t, bp.Utilization(), 2)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"blreynolds4/coffeeshop/units"
 	"testing"
 	"time"
 
@@ -15,7 +16,50 @@ func TestBrewTime(t *testing.T) {
 	brewTime := time.Since(start)
 
 	assert.NotNil(t, c)
-	assert.Equal(t, 1, c.sizeOunces)
+	assert.Equal(t, units.Milliliters(1), c.volume)
 	// brew time should be 1 second-ish
 	assert.InDelta(t, brewTime.Milliseconds(), 1, 5)
 }
+
+func TestBrewMethods(t *testing.T) {
+	tests := []struct {
+		brewer       Brewer
+		method       BrewMethod
+		body         Body
+		cups         int
+		expectedTime int64
+	}{
+		{NewBrewer(1), Drip, MediumBody, 4, 8},
+		{NewPourOverBrewer(10, 1), PourOver, LightBody, 1, 18},
+		{NewFrenchPressBrewer(20, WithCups(8)), FrenchPress, FullBody, 8, 20},
+		{NewAeroPressBrewer(10, 5), AeroPress, MediumBody, 1, 15},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.method, test.brewer.Method())
+		assert.Equal(t, test.cups, test.brewer.Cups())
+
+		start := time.Now()
+		c := test.brewer.Brew(8, Beans{})
+		brewTime := time.Since(start)
+
+		assert.Equal(t, units.Milliliters(8), c.volume)
+		assert.Equal(t, test.method, c.method)
+		assert.Equal(t, test.body, c.body)
+		assert.InDelta(t, test.expectedTime, brewTime.Milliseconds(), 5, test.method.String())
+	}
+}
+
+func TestBrewerWarmUpAndModel(t *testing.T) {
+	b := NewAeroPressBrewer(60, 30,
+		WithBrewModel(NewFixedDuration(5*SimSecond)),
+		WithBrewerWarmUp(WarmUp{Time: 15 * SimSecond}))
+
+	start := time.Now()
+	b.Brew(8, Beans{})
+	assert.InDelta(t, 20, time.Since(start).Milliseconds(), 5)
+
+	start = time.Now()
+	b.Brew(8, Beans{})
+	assert.InDelta(t, 5, time.Since(start).Milliseconds(), 3)
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// carafe holds brewed coffee for an item until it
+// runs out or has been held too long
+type carafe struct {
+	coffee   *Coffee
+	cups     int
+	brewedAt time.Time
+}
+
+// carafeStation keeps the carafes from the batch brewers and the
+// orders waiting on a batch.  It decides how big the next batch
+// should be from the orders it has seen.
+type carafeStation struct {
+	lock     sync.Mutex
+	holdTime time.Duration
+	maxCups  int
+	stats    *statsRecorder
+	// everything is kept by menu item name
+	carafes map[string]*carafe
+	waiting map[string][]*Order
+	brewing map[string]bool
+	demand  map[string][]time.Time
+}
+
+func newCarafeStation(holdTime time.Duration, maxCups int, stats *statsRecorder) *carafeStation {
+	return &carafeStation{
+		holdTime: holdTime,
+		maxCups:  maxCups,
+		stats:    stats,
+		carafes:  map[string]*carafe{},
+		waiting:  map[string][]*Order{},
+		brewing:  map[string]bool{},
+		demand:   map[string][]time.Time{},
+	}
+}
+
+// serves is true if the item should come from a carafe
+func (cs *carafeStation) serves(item MenuItem) bool {
+	return cs != nil && item.BrewableWith(BatchDrip)
+}
+
+// takeOrder pours from a held carafe if it can.  Otherwise the order
+// waits for a batch, and a batch size is returned if the caller
+// needs to start brewing it.
+func (cs *carafeStation) takeOrder(o *Order) (*Coffee, int) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	name := o.Item.Name
+	cs.recordDemand(name)
+	cs.expire(name)
+
+	if c, found := cs.carafes[name]; found {
+		return cs.pour(name, c), 0
+	}
+
+	cs.waiting[name] = append(cs.waiting[name], o)
+	if cs.brewing[name] {
+		return nil, 0
+	}
+
+	cs.brewing[name] = true
+	return nil, cs.batchSize(name)
+}
+
+// fill puts a fresh batch in a carafe and pours for the waiting
+// orders.  If orders are still waiting after the carafe runs out
+// the size of the next batch to brew is returned.
+func (cs *carafeStation) fill(item MenuItem, coffee *Coffee, cups int) ([]*Order, []*Coffee, int) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	name := item.Name
+	cs.brewing[name] = false
+	cs.expire(name)
+
+	// a fresh batch replaces whatever is left in the old carafe
+	if old, found := cs.carafes[name]; found {
+		cs.stats.cupsWasted(old.cups)
+	}
+	// the batch is poured out one cup of the item at a time
+	cup := *coffee
+	cup.volume = item.Size
+	c := &carafe{coffee: &cup, cups: cups, brewedAt: time.Now()}
+	cs.carafes[name] = c
+
+	served := []*Order{}
+	poured := []*Coffee{}
+	for len(cs.waiting[name]) > 0 && c.cups > 0 {
+		if cs.waiting[name][0].isCancelled() {
+			cs.stats.orderCancelled()
+			cs.waiting[name] = cs.waiting[name][1:]
+			continue
+		}
+		served = append(served, cs.waiting[name][0])
+		poured = append(poured, cs.pour(name, c))
+		cs.waiting[name] = cs.waiting[name][1:]
+	}
+
+	if len(cs.waiting[name]) == 0 {
+		return served, poured, 0
+	}
+
+	cs.brewing[name] = true
+	return served, poured, cs.batchSize(name)
+}
+
+// close dumps out whatever is left at the end of the day
+func (cs *carafeStation) close() {
+	if cs == nil {
+		return
+	}
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	for name, c := range cs.carafes {
+		fmt.Println("Dumping", c.cups, "cups of", name)
+		cs.stats.cupsWasted(c.cups)
+		delete(cs.carafes, name)
+	}
+}
+
+// pour a cup from the carafe, the lock must be held
+func (cs *carafeStation) pour(name string, c *carafe) *Coffee {
+	c.cups -= 1
+	if c.cups == 0 {
+		delete(cs.carafes, name)
+	}
+	cs.stats.cupsPoured(1)
+
+	cup := *c.coffee
+	return &cup
+}
+
+// expire throws out a carafe held too long, the lock must be held
+func (cs *carafeStation) expire(name string) {
+	c, found := cs.carafes[name]
+	if !found || time.Since(c.brewedAt) <= cs.holdTime {
+		return
+	}
+
+	fmt.Println("Carafe of", name, "is stale, dumping", c.cups, "cups")
+	cs.stats.cupsWasted(c.cups)
+	delete(cs.carafes, name)
+}
+
+// recordDemand remembers when an item was ordered, the lock must be held
+func (cs *carafeStation) recordDemand(name string) {
+	now := time.Now()
+	recent := []time.Time{}
+	for _, t := range cs.demand[name] {
+		if now.Sub(t) <= cs.holdTime {
+			recent = append(recent, t)
+		}
+	}
+	cs.demand[name] = append(recent, now)
+}
+
+// batchSize brews enough for the waiting orders or for the orders
+// seen over the last hold time, whichever is more, up to what the
+// carafe can hold.  The lock must be held.
+func (cs *carafeStation) batchSize(name string) int {
+	cups := len(cs.waiting[name])
+	if len(cs.demand[name]) > cups {
+		cups = len(cs.demand[name])
+	}
+	if cups > cs.maxCups {
+		cups = cs.maxCups
+	}
+
+	return cups
+}
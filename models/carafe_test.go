@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarafeBatchForWaitingOrders(t *testing.T) {
+	stats := newStatsRecorder()
+	cs := newCarafeStation(time.Minute, 3, stats)
+	item := getTestMenuItem()
+
+	// first order starts a batch, the second waits on it
+	first := NewOrder("first", item)
+	coffee, batchCups := cs.takeOrder(first)
+	assert.Nil(t, coffee)
+	assert.Equal(t, 1, batchCups)
+
+	second := NewOrder("second", item)
+	coffee, batchCups = cs.takeOrder(second)
+	assert.Nil(t, coffee)
+	assert.Equal(t, 0, batchCups)
+
+	// a one cup batch serves the first and needs another for the second
+	served, poured, nextBatchCups := cs.fill(item, &Coffee{volume: item.Size}, 1)
+	assert.Equal(t, []*Order{first}, served)
+	assert.Equal(t, item.Size, poured[0].volume)
+	// both orders were seen within the hold time
+	assert.Equal(t, 2, nextBatchCups)
+
+	served, _, nextBatchCups = cs.fill(item, &Coffee{volume: item.Size * 2}, 2)
+	assert.Equal(t, []*Order{second}, served)
+	assert.Equal(t, 0, nextBatchCups)
+
+	// the extra cup gets poured for the next order
+	coffee, batchCups = cs.takeOrder(NewOrder("third", item))
+	assert.NotNil(t, coffee)
+	assert.Equal(t, item.Size, coffee.volume)
+	assert.Equal(t, 0, batchCups)
+
+	assert.Equal(t, 3, stats.snapshot().CarafeCupsPoured)
+	assert.Equal(t, 0, stats.snapshot().CarafeCupsWasted)
+}
+
+func TestCarafeBatchSizeLimit(t *testing.T) {
+	cs := newCarafeStation(time.Minute, 2, newStatsRecorder())
+	item := getTestMenuItem()
+
+	cs.recordDemand(item.Name)
+	cs.recordDemand(item.Name)
+	_, batchCups := cs.takeOrder(NewOrder("customer", item))
+	assert.Equal(t, 2, batchCups)
+}
+
+func TestCarafeExpires(t *testing.T) {
+	stats := newStatsRecorder()
+	cs := newCarafeStation(5*time.Millisecond, 4, stats)
+	item := getTestMenuItem()
+
+	cs.takeOrder(NewOrder("first", item))
+	cs.fill(item, &Coffee{}, 4)
+
+	// the carafe goes stale and the order waits on a new batch
+	time.Sleep(10 * time.Millisecond)
+	coffee, batchCups := cs.takeOrder(NewOrder("late", item))
+	assert.Nil(t, coffee)
+	assert.Equal(t, 1, batchCups)
+	assert.Equal(t, 3, stats.snapshot().CarafeCupsWasted)
+
+	// anything left at close is wasted
+	cs.fill(item, &Coffee{}, 4)
+	cs.close()
+	assert.Equal(t, 6, stats.snapshot().CarafeCupsWasted)
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SimSecond is how long a simulated second takes.  Working in real
+// seconds made runs take a very long time.
+const SimSecond = time.Millisecond
+
+// SimHour is an hour of simulated time
+const SimHour = 3600 * SimSecond
+
+// SimSeconds converts a duration back to simulated seconds for printing
+func SimSeconds(d time.Duration) float64 {
+	return float64(d) / float64(SimSecond)
+}
+
+// SimDuration is a duration as the simulated time it stands for, for
+// printing.  It's scaled as a float, a simulated second is so much
+// shorter that scaling the nanoseconds would overflow after a couple
+// of hours.
+func SimDuration(d time.Duration) time.Duration {
+	return time.Duration(SimSeconds(d) * float64(time.Second))
+}
+
+// DurationModel is how long a machine takes for an amount of work,
+// grams for a grinder or milliliters for a brewer
+type DurationModel interface {
+	Duration(amount float64) time.Duration
+}
+
+// rateDuration works through the amount at a steady rate
+type rateDuration struct {
+	unitsPerSecond float64
+}
+
+// NewRateDuration takes amount / unitsPerSecond seconds.  A fraction is a
+// slow machine, half a unit a second takes 2 seconds a unit.  A machine
+// can't work at a rate of zero or less so it panics, like time.NewTicker.
+func NewRateDuration(unitsPerSecond float64) DurationModel {
+	if unitsPerSecond <= 0 {
+		panic(fmt.Sprintf("non-positive rate %v for NewRateDuration", unitsPerSecond))
+	}
+
+	return &rateDuration{unitsPerSecond: unitsPerSecond}
+}
+
+func (rd *rateDuration) Duration(amount float64) time.Duration {
+	return time.Duration(amount / rd.unitsPerSecond * float64(SimSecond))
+}
+
+// fixedDuration takes the same time no matter the amount
+type fixedDuration struct {
+	fixed time.Duration
+}
+
+func NewFixedDuration(fixed time.Duration) DurationModel {
+	return &fixedDuration{fixed: fixed}
+}
+
+func (fd *fixedDuration) Duration(float64) time.Duration {
+	return fd.fixed
+}
+
+// fixedPlusRateDuration is a fixed setup time then a steady rate
+type fixedPlusRateDuration struct {
+	fixed time.Duration
+	rate  DurationModel
+}
+
+func NewFixedPlusRateDuration(fixed time.Duration, unitsPerSecond float64) DurationModel {
+	return &fixedPlusRateDuration{
+		fixed: fixed,
+		rate:  NewRateDuration(unitsPerSecond),
+	}
+}
+
+func (fr *fixedPlusRateDuration) Duration(amount float64) time.Duration {
+	return fr.fixed + fr.rate.Duration(amount)
+}
+
+// lockedRand lets the random models be shared by machines
+// running in different goroutines
+type lockedRand struct {
+	lock *sync.Mutex
+	rng  *rand.Rand
+}
+
+func newLockedRand(seed int64) lockedRand {
+	return lockedRand{
+		lock: &sync.Mutex{},
+		rng:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (lr lockedRand) float64() float64 {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+
+	return lr.rng.Float64()
+}
+
+func (lr lockedRand) normal() float64 {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+
+	return lr.rng.NormFloat64()
+}
+
+// normalDuration spreads the base time out with a normal distribution
+type normalDuration struct {
+	base   DurationModel
+	stdDev float64
+	rand   lockedRand
+}
+
+// NewNormalDuration varies the base time by a normal distribution with a
+// standard deviation that is a fraction of the base time.  It never
+// goes below zero.
+func NewNormalDuration(base DurationModel, stdDevFraction float64, seed int64) DurationModel {
+	return &normalDuration{
+		base:   base,
+		stdDev: stdDevFraction,
+		rand:   newLockedRand(seed),
+	}
+}
+
+func (nd *normalDuration) Duration(amount float64) time.Duration {
+	factor := 1 + nd.rand.normal()*nd.stdDev
+	if factor < 0 {
+		factor = 0
+	}
+
+	return time.Duration(float64(nd.base.Duration(amount)) * factor)
+}
+
+// lognormalDuration has the long tail of real machines,
+// mostly on time with the occasional slow run
+type lognormalDuration struct {
+	base  DurationModel
+	sigma float64
+	rand  lockedRand
+}
+
+// NewLognormalDuration varies the base time by a lognormal distribution
+// that keeps the base time as its mean
+func NewLognormalDuration(base DurationModel, sigma float64, seed int64) DurationModel {
+	return &lognormalDuration{
+		base:  base,
+		sigma: sigma,
+		rand:  newLockedRand(seed),
+	}
+}
+
+func (ld *lognormalDuration) Duration(amount float64) time.Duration {
+	factor := math.Exp(ld.rand.normal()*ld.sigma - ld.sigma*ld.sigma/2)
+	return time.Duration(float64(ld.base.Duration(amount)) * factor)
+}
+
+// WarmUp is the extra time a cold machine takes before it can work.
+// A machine is cold the first time it's used, and again if it sits
+// idle longer than IdleAfter.  A zero IdleAfter never cools down.
+type WarmUp struct {
+	Time      time.Duration
+	IdleAfter time.Duration
+}
+
+// warmer tracks when a machine was last used
+type warmer struct {
+	lock     *sync.Mutex
+	warmUp   WarmUp
+	lastUsed time.Time
+	inUse    int
+}
+
+func newWarmer() warmer {
+	return warmer{lock: &sync.Mutex{}}
+}
+
+// start returns the warm up time needed to start working now
+func (w *warmer) start() time.Duration {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	cold := w.inUse == 0 && (w.lastUsed.IsZero() ||
+		(w.warmUp.IdleAfter > 0 && time.Since(w.lastUsed) > w.warmUp.IdleAfter))
+	w.inUse += 1
+
+	if cold {
+		return w.warmUp.Time
+	}
+
+	return 0
+}
+
+func (w *warmer) done() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.inUse -= 1
+	w.lastUsed = time.Now()
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateDuration(t *testing.T) {
+	slow := NewRateDuration(2)
+	fast := NewRateDuration(4)
+
+	// a faster machine takes less time
+	assert.Equal(t, 5*SimSecond, slow.Duration(10))
+	assert.Equal(t, 2500*time.Microsecond, fast.Duration(10))
+
+	// a fraction of a unit a second is a slow machine
+	assert.Equal(t, 20*SimSecond, NewRateDuration(0.5).Duration(10))
+
+	// a machine that doesn't work isn't a rate
+	assert.Panics(t, func() { NewRateDuration(0) })
+	assert.Panics(t, func() { NewRateDuration(-1) })
+}
+
+func TestSimDuration(t *testing.T) {
+	assert.Equal(t, 90*time.Second, SimDuration(90*SimSecond))
+
+	// a long day doesn't overflow
+	assert.Equal(t, 8*time.Hour, SimDuration(8*SimHour))
+}
+
+func TestFixedDurations(t *testing.T) {
+	assert.Equal(t, 30*SimSecond, NewFixedDuration(30*SimSecond).Duration(100))
+	assert.Equal(t, 34*SimSecond, NewFixedPlusRateDuration(30*SimSecond, 2).Duration(8))
+}
+
+func TestRandomDurationsAreSeeded(t *testing.T) {
+	base := NewFixedDuration(100 * SimSecond)
+	models := map[string]func(int64) DurationModel{
+		"normal": func(seed int64) DurationModel {
+			return NewNormalDuration(base, 0.2, seed)
+		},
+		"lognormal": func(seed int64) DurationModel {
+			return NewLognormalDuration(base, 0.5, seed)
+		},
+	}
+
+	for name, newModel := range models {
+		first := newModel(42)
+		second := newModel(42)
+
+		total := time.Duration(0)
+		for i := 0; i < 2000; i++ {
+			d := first.Duration(1)
+			assert.Equal(t, d, second.Duration(1), name)
+			assert.GreaterOrEqual(t, d, time.Duration(0), name)
+			total += d
+		}
+
+		// both keep the base time as the mean
+		mean := total / 2000
+		assert.InDelta(t, float64(100*SimSecond), float64(mean), float64(5*SimSecond), name)
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	w := newWarmer()
+	w.warmUp = WarmUp{Time: 10 * SimSecond, IdleAfter: 5 * time.Millisecond}
+
+	// cold on first use
+	assert.Equal(t, 10*SimSecond, w.start())
+	w.done()
+
+	// warm right after
+	assert.Equal(t, time.Duration(0), w.start())
+	w.done()
+
+	// cold again after sitting idle
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 10*SimSecond, w.start())
+
+	// a machine in use doesn't cool down
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), w.start())
+}
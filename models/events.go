@@ -0,0 +1,119 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// how many events a subscriber can fall behind before
+// they start missing them
+const subscriberBuffer = 100
+
+type ShopEventKind string
+
+const (
+	// an order moved to a new status
+	StatusEvent ShopEventKind = "status"
+	// the barista got to the next step of an order
+	StepEvent ShopEventKind = "step"
+	// a grinder or brewer was taken or put back
+	PoolEvent ShopEventKind = "pool"
+)
+
+// ShopEvent is something that happened in the shop,
+// for screens showing what's going on
+type ShopEvent struct {
+	Kind     ShopEventKind
+	OrderID  int64
+	Customer string
+	Status   OrderStatus
+	// Step is the barista's order event, like GrinderAvailable
+	Step    string
+	Barista string
+	// what's free in the pools for pool events
+	GrindersFree    int
+	BrewerSlotsFree int
+	At              time.Time
+}
+
+// Finished is true for the last event of an order
+func (e ShopEvent) Finished() bool {
+	return e.Kind == StatusEvent && (e.Status == Complete || e.Status == Cancelled)
+}
+
+type subscription struct {
+	// 0 is every event in the shop
+	orderID int64
+	events  chan ShopEvent
+}
+
+// eventBus hands shop events to subscribers without waiting on
+// them, a subscriber that falls too far behind misses events
+type eventBus struct {
+	lock        *sync.Mutex
+	subscribers map[*subscription]bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		lock:        &sync.Mutex{},
+		subscribers: map[*subscription]bool{},
+	}
+}
+
+// subscribe to the events of an order, or every event for 0.  The
+// returned func unsubscribes and closes the channel.
+func (eb *eventBus) subscribe(orderID int64) (<-chan ShopEvent, func()) {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	sub := &subscription{orderID: orderID, events: make(chan ShopEvent, subscriberBuffer)}
+	eb.subscribers[sub] = true
+
+	return sub.events, func() {
+		eb.lock.Lock()
+		defer eb.lock.Unlock()
+
+		if eb.subscribers[sub] {
+			delete(eb.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// publish is safe on a nil bus for baristas and orders outside a shop
+func (eb *eventBus) publish(event ShopEvent) {
+	if eb == nil {
+		return
+	}
+
+	event.At = time.Now()
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	for sub := range eb.subscribers {
+		if sub.orderID != 0 && sub.orderID != event.OrderID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// stepName is the name of the barista's order event
+func stepName(event OrderEvent) string {
+	switch {
+	case isGrinderAvailable(event):
+		return "GrinderAvailable"
+	case isGrindComplete(event):
+		return "GrindComplete"
+	case isBrewerAvailable(event):
+		return "BrewerAvailable"
+	case isCoffeeComplete(event):
+		return "CoffeeComplete"
+	}
+
+	return "unknown step"
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := newEventBus()
+	all, stopAll := bus.subscribe(0)
+	one, stopOne := bus.subscribe(1)
+
+	bus.publish(ShopEvent{Kind: StatusEvent, OrderID: 1, Status: Grinding})
+	bus.publish(ShopEvent{Kind: StatusEvent, OrderID: 2, Status: Brewing})
+
+	assert.Equal(t, int64(1), (<-all).OrderID)
+	assert.Equal(t, int64(2), (<-all).OrderID)
+	event := <-one
+	assert.Equal(t, Grinding, event.Status)
+	assert.False(t, event.At.IsZero())
+	assert.Len(t, one, 0)
+
+	// stopping closes the channel, and stopping twice is fine
+	stopOne()
+	stopOne()
+	_, open := <-one
+	assert.False(t, open)
+
+	// a subscriber that falls behind misses events instead of blocking
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.publish(ShopEvent{Kind: PoolEvent})
+	}
+	assert.Len(t, all, subscriberBuffer)
+	stopAll()
+
+	// baristas outside a shop don't have a bus
+	var none *eventBus
+	none.publish(ShopEvent{})
+}
+
+func TestShopEvents(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+	events, stop := shop.Subscribe(0)
+	defer stop()
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+
+	statuses := []OrderStatus{}
+	steps := []string{}
+	pools := 0
+	for event := range events {
+		switch event.Kind {
+		case StatusEvent:
+			statuses = append(statuses, event.Status)
+		case StepEvent:
+			steps = append(steps, event.Step)
+		case PoolEvent:
+			pools += 1
+		}
+		if event.Finished() {
+			break
+		}
+	}
+
+	assert.Equal(t, []OrderStatus{Ordered, ReadyToGrind, Grinding, ReadyToBrew, Brewing, Complete}, statuses)
+	assert.Equal(t, []string{"GrinderAvailable", "GrindComplete", "BrewerAvailable", "CoffeeComplete"}, steps)
+	// the grinder and brewer taken and put back
+	assert.Equal(t, 4, pools)
+	shop.Close()
+}
@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/units"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// equipment built outside the models package
+
+type instantGrinder struct{}
+
+func (ig *instantGrinder) Grind(beans models.Beans) models.Beans {
+	return models.NewBeans(beans.Weight(), beans.Variety())
+}
+
+func (ig *instantGrinder) Loaded() (models.BeanVariety, bool) {
+	return models.BeanVariety{}, false
+}
+
+func (ig *instantGrinder) Accepts(models.BeanVariety) bool {
+	return true
+}
+
+type instantBrewer struct{}
+
+func (ib *instantBrewer) Brew(finishedVolume units.Milliliters, beans models.Beans) *models.Coffee {
+	return models.NewCoffee(finishedVolume, beans, ib)
+}
+
+func (ib *instantBrewer) Method() models.BrewMethod {
+	return models.AeroPress
+}
+
+func (ib *instantBrewer) Cups() int {
+	return 1
+}
+
+func TestExternalEquipment(t *testing.T) {
+	item := models.MenuItem{
+		Name:        "Test",
+		Size:        250,
+		CoffeeRatio: 12.5,
+		Variety:     models.BeanVariety{Origin: "Test", Roast: models.LightRoast},
+	}
+	brewer := &instantBrewer{}
+	shop := models.NewCoffeeShop(models.Menu{item}, 1, 1, 1,
+		models.NewGrinderPool(&instantGrinder{}),
+		models.NewBrewerPool(brewer))
+
+	start := time.Now()
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("customer", item)
+	shop.LeaveOrderingKiosk(kiosk)
+
+	coffee := order.Wait()
+	shop.Close()
+
+	assert.Equal(t, item, coffee.Item())
+	assert.Equal(t, units.Milliliters(250), coffee.Volume())
+	assert.Equal(t, units.Ratio(12.5), coffee.Ratio())
+	assert.Equal(t, item.Variety, coffee.Variety())
+	assert.Equal(t, models.AeroPress, coffee.Method())
+	assert.Equal(t, models.MediumBody, coffee.Body())
+	assert.Equal(t, brewer, coffee.Brewer())
+	assert.False(t, coffee.BrewedAt().Before(start))
+}
+
+func TestBeansAccessors(t *testing.T) {
+	variety := models.BeanVariety{Origin: "Kenya", Roast: models.LightRoast}
+	beans := models.NewBeans(18.5, variety)
+
+	assert.Equal(t, units.Grams(18.5), beans.Weight())
+	assert.Equal(t, variety, beans.Variety())
+}
@@ -1,29 +1,145 @@
 package models
 
 import (
+	"blreynolds4/coffeeshop/units"
 	"fmt"
 	"time"
 )
 
+// default time to clear out the old beans when changing varieties
+const DefaultPurgeSeconds = 20
+
 type Grinder interface {
 	Grind(beans Beans) Beans
+	// Loaded returns the variety left in the grinder from the last grind
+	Loaded() (BeanVariety, bool)
+	// Accepts is false when the grinder is dedicated to another variety
+	Accepts(variety BeanVariety) bool
 }
 
 type grinder struct {
-	gramsPerSecond int
+	speed        DurationModel
+	purgeSeconds int
+	// percent of the normal grind time for each roast, darker roasts
+	// are more brittle and grind faster than dense light roasts
+	roastFactors map[RoastLevel]int
+	loaded       BeanVariety
+	isLoaded     bool
+	dedicated    bool
+	warmer
+}
+
+type GrinderOption func(*grinder)
+
+// WithGrindModel replaces the steady grams per second grind time
+func WithGrindModel(speed DurationModel) GrinderOption {
+	return func(g *grinder) {
+		g.speed = speed
+	}
+}
+
+// WithGrinderWarmUp makes a cold grinder take extra time
+func WithGrinderWarmUp(warmUp WarmUp) GrinderOption {
+	return func(g *grinder) {
+		g.warmUp = warmUp
+	}
+}
+
+// WithPurgeSeconds sets the changeover delay when switching varieties
+func WithPurgeSeconds(seconds int) GrinderOption {
+	return func(g *grinder) {
+		g.purgeSeconds = seconds
+	}
+}
+
+// WithRoastFactor sets the percent of the normal grind time for a roast
+func WithRoastFactor(roast RoastLevel, percent int) GrinderOption {
+	return func(g *grinder) {
+		g.roastFactors[roast] = percent
+	}
+}
+
+// WithLoadedVariety starts the grinder with a variety already in it
+func WithLoadedVariety(variety BeanVariety) GrinderOption {
+	return func(g *grinder) {
+		g.loaded = variety
+		g.isLoaded = true
+	}
+}
+
+// WithDedicatedVariety loads the grinder with a variety and
+// keeps it from grinding anything else
+func WithDedicatedVariety(variety BeanVariety) GrinderOption {
+	return func(g *grinder) {
+		WithLoadedVariety(variety)(g)
+		g.dedicated = true
+	}
 }
 
-func NewGrinder(gramsPerSecond int) Grinder {
-	return &grinder{
-		gramsPerSecond: gramsPerSecond,
+// DefaultRoastFactor is the percent of the normal grind time a roast
+// takes, darker roasts are more brittle and grind faster than dense
+// light roasts
+func DefaultRoastFactor(roast RoastLevel) int {
+	switch roast {
+	case LightRoast:
+		return 120
+	case DarkRoast:
+		return 80
 	}
+
+	return 100
+}
+
+func NewGrinder(gramsPerSecond units.GramsPerSecond, opts ...GrinderOption) Grinder {
+	result := &grinder{
+		speed:        NewRateDuration(float64(gramsPerSecond)),
+		purgeSeconds: DefaultPurgeSeconds,
+		roastFactors: map[RoastLevel]int{
+			LightRoast:  DefaultRoastFactor(LightRoast),
+			MediumRoast: DefaultRoastFactor(MediumRoast),
+			DarkRoast:   DefaultRoastFactor(DarkRoast),
+		},
+		warmer: newWarmer(),
+	}
+
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	return result
+}
+
+func (g *grinder) Loaded() (BeanVariety, bool) {
+	return g.loaded, g.isLoaded
+}
+
+func (g *grinder) Accepts(variety BeanVariety) bool {
+	return !g.dedicated || g.loaded == variety
 }
 
 func (g *grinder) Grind(beans Beans) Beans {
+	if warmUp := g.start(); warmUp > 0 {
+		fmt.Printf("Warming up grinder for %.1f Seconds\n", SimSeconds(warmUp))
+		time.Sleep(warmUp)
+	}
+	defer g.done()
+
+	// purge the old beans if we're switching varieties
+	if g.isLoaded && g.loaded != beans.variety {
+		fmt.Printf("Purging %s for %d Seconds\n", g.loaded, g.purgeSeconds)
+		time.Sleep(time.Duration(g.purgeSeconds) * SimSecond)
+	}
+	g.loaded = beans.variety
+	g.isLoaded = true
+
 	// Wait for the time it would take to grind the beans
-	grindSeconds := g.gramsPerSecond * beans.weightGrams
-	fmt.Printf("Grinding %d grams for %d Seconds\n", beans.weightGrams, grindSeconds)
-	time.Sleep(time.Duration(grindSeconds) * time.Millisecond)
+	factor, found := g.roastFactors[beans.variety.Roast]
+	if !found {
+		factor = 100
+	}
+	grindTime := g.speed.Duration(float64(beans.weight)) * time.Duration(factor) / 100
+	fmt.Printf("Grinding %s for %.1f Seconds\n", beans.weight, SimSeconds(grindTime))
+	time.Sleep(grindTime)
 	fmt.Println("Grind Complete")
 	return beans
 }
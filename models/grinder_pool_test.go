@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +29,94 @@ func TestWaitForGrinder(t *testing.T) {
 	assert.NotNil(t, readGrinder)
 	assert.Equal(t, g1, readGrinder)
 }
+
+func TestGrinderPoolPrefersLoadedVariety(t *testing.T) {
+	house := BeanVariety{Origin: "House", Roast: MediumRoast}
+	decaf := BeanVariety{Origin: "House", Roast: MediumRoast, Decaf: true}
+	g1 := NewGrinder(4, WithLoadedVariety(house))
+	g2 := NewGrinder(4, WithLoadedVariety(decaf))
+	gp := NewGrinderPool(g1, g2)
+
+	assert.Equal(t, g2, gp.GetGrinderFor(decaf))
+	// the only one left gets used even though it needs a purge
+	assert.Equal(t, g1, gp.GetGrinderFor(decaf))
+}
+
+func TestWaitForDedicatedGrinder(t *testing.T) {
+	house := BeanVariety{Origin: "House", Roast: MediumRoast}
+	decaf := BeanVariety{Origin: "House", Roast: MediumRoast, Decaf: true}
+	dedicated := NewGrinder(4, WithDedicatedVariety(decaf))
+	regular := NewGrinder(4)
+	gp := NewGrinderPool(dedicated)
+
+	go func() {
+		gp.AddGrinder(regular)
+	}()
+
+	// house beans have to wait for the regular grinder
+	assert.Equal(t, regular, gp.GetGrinderFor(house))
+}
+
+func TestRemoveGrinder(t *testing.T) {
+	g1 := NewGrinder(4)
+	g2 := NewGrinder(4)
+	gp := NewGrinderPool(g1, g2)
+
+	// an idle grinder comes out right away
+	gp.RemoveGrinder(g1)
+	assert.Equal(t, g2, gp.GetGrinder())
+
+	// one in use is taken out when it's put back
+	gp.RemoveGrinder(g2)
+	gp.AddGrinder(g2)
+	gp.AddGrinder(g1)
+	assert.Equal(t, g1, gp.GetGrinder())
+}
+
+func TestRestoreGrinder(t *testing.T) {
+	g1 := NewGrinder(4)
+	gp := NewGrinderPool(g1)
+
+	// an idle grinder goes straight back
+	gp.RemoveGrinder(g1)
+	assert.Equal(t, 0, gp.Available())
+	gp.RestoreGrinder(g1)
+	assert.Equal(t, 1, gp.Available())
+
+	// restoring one in use drops the removal waiting on it,
+	// it's back in the pool once it's returned
+	assert.Equal(t, g1, gp.GetGrinder())
+	gp.RemoveGrinder(g1)
+	gp.RestoreGrinder(g1)
+	assert.Equal(t, 0, gp.Available())
+	gp.AddGrinder(g1)
+	assert.Equal(t, 1, gp.Available())
+	assert.False(t, gp.Utilization()[0].InUse)
+}
+
+func TestGrinderUtilizationAndWaiting(t *testing.T) {
+	g1 := NewGrinder(4)
+	gp := NewGrinderPool(g1)
+	assert.Equal(t, []GrinderUsage{{Grinder: "Grinder-0"}}, gp.Utilization())
+
+	grinder := gp.GetGrinder()
+	assert.Equal(t, 0, gp.Available())
+
+	// someone waits on the grinder in use
+	got := make(chan Grinder)
+	go func() {
+		got <- gp.GetGrinder()
+	}()
+	assert.Eventually(t, func() bool {
+		return gp.Waiting() == 1
+	}, time.Second, time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+	gp.AddGrinder(grinder)
+	assert.Equal(t, g1, <-got)
+	assert.Equal(t, 0, gp.Waiting())
+
+	usage := gp.Utilization()
+	assert.Len(t, usage, 1)
+	assert.GreaterOrEqual(t, usage[0].Busy, 5*time.Millisecond)
+}
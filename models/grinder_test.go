@@ -1,6 +1,7 @@
 package models
 
 import (
+	"blreynolds4/coffeeshop/units"
 	"testing"
 	"time"
 
@@ -12,10 +13,84 @@ func TestGrindTime(t *testing.T) {
 	b := NewGrinder(1)
 
 	start := time.Now()
-	grounds := b.Grind(Beans{weightGrams: 1})
+	grounds := b.Grind(Beans{weight: 1})
 	grindTime := time.Since(start)
 
-	assert.Equal(t, 1, grounds.weightGrams)
+	assert.Equal(t, units.Grams(1), grounds.weight)
 	// Grind time should be 1 second-ish
 	assert.InDelta(t, grindTime.Seconds(), 1, 5)
 }
+
+func TestGrindTimeByRoast(t *testing.T) {
+	g := NewGrinder(1, WithRoastFactor(DarkRoast, 50))
+
+	start := time.Now()
+	grounds := g.Grind(Beans{weight: 20, variety: BeanVariety{Roast: DarkRoast}})
+	grindTime := time.Since(start)
+
+	assert.Equal(t, units.Grams(20), grounds.weight)
+	// dark roast at half the time should be 10ms-ish
+	assert.InDelta(t, 10, grindTime.Milliseconds(), 5)
+}
+
+func TestPurgeOnVarietyChange(t *testing.T) {
+	house := BeanVariety{Origin: "House", Roast: MediumRoast}
+	decaf := BeanVariety{Origin: "House", Roast: MediumRoast, Decaf: true}
+	g := NewGrinder(1, WithPurgeSeconds(30), WithLoadedVariety(house))
+
+	// same variety, no purge
+	start := time.Now()
+	g.Grind(Beans{weight: 1, variety: house})
+	assert.Less(t, time.Since(start).Milliseconds(), int64(15))
+
+	// switching varieties takes the purge time
+	start = time.Now()
+	g.Grind(Beans{weight: 1, variety: decaf})
+	assert.GreaterOrEqual(t, time.Since(start).Milliseconds(), int64(30))
+
+	loaded, isLoaded := g.Loaded()
+	assert.True(t, isLoaded)
+	assert.Equal(t, decaf, loaded)
+}
+
+func TestDedicatedGrinder(t *testing.T) {
+	house := BeanVariety{Origin: "House", Roast: MediumRoast}
+	decaf := BeanVariety{Origin: "House", Roast: MediumRoast, Decaf: true}
+	g := NewGrinder(1, WithDedicatedVariety(decaf))
+
+	assert.True(t, g.Accepts(decaf))
+	assert.False(t, g.Accepts(house))
+
+	// a regular grinder takes anything
+	assert.True(t, NewGrinder(1).Accepts(decaf))
+}
+
+func TestFasterGrinderIsFaster(t *testing.T) {
+	slow := NewGrinder(1)
+	fast := NewGrinder(10)
+
+	start := time.Now()
+	slow.Grind(Beans{weight: 20})
+	slowTime := time.Since(start)
+
+	start = time.Now()
+	fast.Grind(Beans{weight: 20})
+	fastTime := time.Since(start)
+
+	assert.Less(t, fastTime, slowTime)
+}
+
+func TestGrinderWarmUp(t *testing.T) {
+	g := NewGrinder(10,
+		WithGrinderWarmUp(WarmUp{Time: 20 * SimSecond}),
+		WithGrindModel(NewFixedDuration(0)))
+
+	start := time.Now()
+	g.Grind(Beans{weight: 1})
+	assert.GreaterOrEqual(t, time.Since(start), 20*SimSecond)
+
+	// warm now, so it's quick
+	start = time.Now()
+	g.Grind(Beans{weight: 1})
+	assert.Less(t, time.Since(start), 10*SimSecond)
+}
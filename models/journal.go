@@ -0,0 +1,287 @@
+package models
+
+import (
+	"blreynolds4/coffeeshop/units"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// the kinds of journal records
+const (
+	// an order placed, or put back in line after a restart,
+	// with everything needed to make it
+	orderRecord = "order"
+	// an order moved to a new status
+	statusRecord = "status"
+)
+
+// journalRecord is a line of the journal
+type journalRecord struct {
+	Op       string `json:"op"`
+	ID       int64  `json:"id"`
+	Customer string `json:"customer,omitempty"`
+	Item     string `json:"item,omitempty"`
+	Status   string `json:"status"`
+	// the ground beans once the order is ready to brew
+	BeansGrams float64   `json:"beans_grams,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Journal is a write-ahead log of the orders placed at the kiosks and
+// every status they move to, so the orders in progress can be finished
+// after a crash.  Each record is a line of JSON that's synced before
+// the shop moves on when the journal is a file.
+type Journal struct {
+	lock *sync.Mutex
+	out  io.Writer
+}
+
+type syncer interface {
+	Sync() error
+}
+
+func NewJournal(out io.Writer) *Journal {
+	return &Journal{
+		lock: &sync.Mutex{},
+		out:  out,
+	}
+}
+
+// OpenJournalFile recovers the orders that weren't finished from the
+// journal at the path, if there is one, and opens it to add to.  A torn
+// last line is cut off so the new records follow the good ones, on a
+// line of their own if the last good one wasn't ended.
+func OpenJournalFile(path string, menu Menu) (*Journal, []*Order, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, good, err := recoverOrders(file, menu)
+	if err == nil {
+		err = file.Truncate(good)
+	}
+	if err == nil {
+		_, err = file.Seek(good, io.SeekStart)
+	}
+	if err == nil && good > 0 {
+		last := []byte{0}
+		if _, err = file.ReadAt(last, good-1); err == nil && last[0] != '\n' {
+			_, err = file.Write([]byte("\n"))
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return NewJournal(file), orders, nil
+}
+
+// Close closes the journal's file, if it's a file
+func (j *Journal) Close() error {
+	if closer, canClose := j.out.(io.Closer); canClose {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// WithJournal records the orders placed at the kiosks and their statuses
+func WithJournal(journal *Journal) ShopOption {
+	return func(cs *coffeeShop) {
+		cs.journal = journal
+	}
+}
+
+// placed records an order with what's needed to make it, the done
+// flag lock is held or the order isn't shared yet.  It's safe on a
+// nil journal.
+func (j *Journal) placed(o *Order) {
+	if j == nil {
+		return
+	}
+
+	record := j.statusRecord(o)
+	record.Op = orderRecord
+	record.Customer = o.Customer
+	record.Item = o.Item.Name
+	j.write(record)
+}
+
+// statusChanged records the order's new status, the done flag lock is held
+func (j *Journal) statusChanged(o *Order) {
+	if j == nil {
+		return
+	}
+
+	j.write(j.statusRecord(o))
+}
+
+func (j *Journal) statusRecord(o *Order) journalRecord {
+	result := journalRecord{
+		Op:     statusRecord,
+		ID:     o.ID,
+		Status: o.Status.String(),
+		At:     time.Now(),
+	}
+	if o.Status == ReadyToBrew || o.Status == Brewing {
+		result.BeansGrams = float64(o.GroundBeans.weight)
+	}
+
+	return result
+}
+
+func (j *Journal) write(record journalRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		fmt.Println("Error journaling order", record.ID, err)
+		return
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if _, err := fmt.Fprintf(j.out, "%s\n", line); err != nil {
+		fmt.Println("Error journaling order", record.ID, err)
+		return
+	}
+	if s, canSync := j.out.(syncer); canSync {
+		if err := s.Sync(); err != nil {
+			fmt.Println("Error syncing the journal", err)
+		}
+	}
+}
+
+// RecoverOrders reads a journal and returns the orders that weren't
+// complete or cancelled, in the order they were placed, set back to
+// their last safe step.  Orders that were grinding start over, ground
+// beans are kept and orders that were brewing brew again.  A torn last
+// line from a crash mid-write is skipped.
+func RecoverOrders(journal io.Reader, menu Menu) ([]*Order, error) {
+	orders, _, err := recoverOrders(journal, menu)
+	return orders, err
+}
+
+// recoverOrders also returns the length of the journal
+// up to a torn last line, with the good last line's newline
+// if it has one
+func recoverOrders(journal io.Reader, menu Menu) ([]*Order, int64, error) {
+	orders := map[int64]*Order{}
+	placed := []int64{}
+	maxID := int64(0)
+	good := int64(0)
+
+	// the lines are counted as they're read, the
+	// last one may not have a newline
+	read := int64(0)
+	scanner := bufio.NewScanner(journal)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		read += int64(advance)
+		return advance, token, err
+	})
+	var torn error
+	for line := 1; scanner.Scan(); line++ {
+		if torn != nil {
+			return nil, 0, torn
+		}
+
+		record := journalRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// only the last line can be torn
+			torn = fmt.Errorf("journal line %d: %w", line, err)
+			continue
+		}
+		good = read
+		if record.ID > maxID {
+			maxID = record.ID
+		}
+
+		status, known := parseOrderStatus(record.Status)
+		if !known {
+			return nil, 0, fmt.Errorf("journal line %d: unknown status %q", line, record.Status)
+		}
+
+		order, found := orders[record.ID]
+		switch {
+		case record.Op == orderRecord && !found:
+			item, onMenu := menu.Item(record.Item)
+			if !onMenu {
+				return nil, 0, fmt.Errorf("journal line %d: %q isn't on the menu", line, record.Item)
+			}
+			order = newRecoveredOrder(record.ID, record.Customer, item, record.At)
+			orders[record.ID] = order
+			placed = append(placed, record.ID)
+		case !found:
+			return nil, 0, fmt.Errorf("journal line %d: order %d was never placed", line, record.ID)
+		}
+
+		if status != order.Status {
+			order.timeline = append(order.timeline, StatusChange{Status: status, At: record.At})
+		}
+		order.Status = status
+		order.GroundBeans = Beans{weight: units.Grams(record.BeansGrams), variety: order.Item.Variety}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// new orders can't reuse the IDs in the journal
+	for {
+		last := atomic.LoadInt64(&lastOrderID)
+		if last >= maxID || atomic.CompareAndSwapInt64(&lastOrderID, last, maxID) {
+			break
+		}
+	}
+
+	result := []*Order{}
+	for _, id := range placed {
+		order := orders[id]
+		safe := Ordered
+		switch order.Status {
+		case Complete, Cancelled:
+			continue
+		case ReadyToBrew, Brewing:
+			safe = ReadyToBrew
+		default:
+			order.GroundBeans = Beans{}
+		}
+		if safe != order.Status {
+			order.Status = safe
+			order.timeline = append(order.timeline, StatusChange{Status: safe, At: time.Now()})
+		}
+		result = append(result, order)
+	}
+
+	return result, good, nil
+}
+
+// newRecoveredOrder is an order from the journal with its first ID
+func newRecoveredOrder(id int64, customer string, item MenuItem, orderedAt time.Time) *Order {
+	return &Order{
+		ID:        id,
+		Customer:  customer,
+		Item:      item,
+		Status:    Ordered,
+		doneFlag:  sync.NewCond(&sync.Mutex{}),
+		orderedAt: orderedAt,
+		timeline:  []StatusChange{{Status: Ordered, At: orderedAt}},
+	}
+}
+
+func parseOrderStatus(name string) (OrderStatus, bool) {
+	for status := Ordered; status <= Cancelled; status++ {
+		if status.String() == name {
+			return status, true
+		}
+	}
+
+	return Ordered, false
+}
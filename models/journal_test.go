@@ -0,0 +1,290 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the crash test runs a shop in a child process
+// journaling to the file in this variable
+const crashJournalEnv = "COFFEESHOP_CRASH_JOURNAL"
+
+const crashOrderCount = 12
+
+func TestJournalRecords(t *testing.T) {
+	journal := &bytes.Buffer{}
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers(),
+		WithJournal(NewJournal(journal)))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+	shop.Close()
+
+	lines := strings.Split(strings.TrimSpace(journal.String()), "\n")
+	assert.Contains(t, lines[0], `"op":"order"`)
+	assert.Contains(t, lines[0], `"customer":"test"`)
+	assert.Contains(t, lines[0], `"item":"Regular Coffee"`)
+	assert.Contains(t, lines[len(lines)-1], `"status":"Complete"`)
+	assert.Len(t, lines, len(order.Timeline()))
+
+	// a finished order has nothing to recover
+	orders, err := RecoverOrders(journal, Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestRecoverOrdersAtSafeStep(t *testing.T) {
+	at := time.Now().Format(time.RFC3339Nano)
+	journal := strings.Join([]string{
+		`{"op":"order","id":9001,"customer":"a","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"order","id":9002,"customer":"b","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"order","id":9003,"customer":"c","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"order","id":9004,"customer":"d","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"status","id":9001,"status":"Ready to Grind","at":"` + at + `"}`,
+		`{"op":"status","id":9001,"status":"Grinding","at":"` + at + `"}`,
+		`{"op":"status","id":9002,"status":"Ready to Grind","at":"` + at + `"}`,
+		`{"op":"status","id":9002,"status":"Grinding","at":"` + at + `"}`,
+		`{"op":"status","id":9002,"status":"Ready to Brew","beans_grams":15,"at":"` + at + `"}`,
+		`{"op":"status","id":9002,"status":"Brewing","beans_grams":15,"at":"` + at + `"}`,
+		`{"op":"status","id":9003,"status":"Cancelled","at":"` + at + `"}`,
+		`{"op":"status","id":9004,"status":"Complete","at":"` + at + `"}`,
+		`{"op":"status","id":90`,
+	}, "\n")
+
+	orders, err := RecoverOrders(strings.NewReader(journal), Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+
+	// grinding starts over
+	assert.Equal(t, int64(9001), orders[0].ID)
+	assert.Equal(t, "a", orders[0].Customer)
+	assert.Equal(t, Ordered, orders[0].GetStatus())
+	assert.Equal(t, Beans{}, orders[0].GroundBeans)
+	assert.Equal(t, Ordered, orders[0].Timeline()[len(orders[0].Timeline())-1].Status)
+
+	// brewing keeps the ground beans
+	assert.Equal(t, int64(9002), orders[1].ID)
+	assert.Equal(t, ReadyToBrew, orders[1].GetStatus())
+	assert.Equal(t, 15.0, float64(orders[1].GroundBeans.Weight()))
+	assert.Len(t, orders[1].Timeline(), 6)
+
+	// new orders get new IDs
+	assert.Greater(t, NewOrder("new", getTestMenuItem()).ID, int64(9004))
+}
+
+func TestRecoverOrdersRequeued(t *testing.T) {
+	at := time.Now().Format(time.RFC3339Nano)
+	journal := strings.Join([]string{
+		`{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"status","id":1,"status":"Ready to Brew","beans_grams":15,"at":"` + at + `"}`,
+		`{"op":"status","id":1,"status":"Brewing","beans_grams":15,"at":"` + at + `"}`,
+		// put back in line after a restart then crashed again
+		`{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Ready to Brew","beans_grams":15,"at":"` + at + `"}`,
+	}, "\n")
+
+	orders, err := RecoverOrders(strings.NewReader(journal), Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, ReadyToBrew, orders[0].GetStatus())
+	assert.Equal(t, 15.0, float64(orders[0].GroundBeans.Weight()))
+}
+
+func TestRecoverOrdersErrors(t *testing.T) {
+	menu := Menu{getTestMenuItem()}
+	for name, journal := range map[string]string{
+		"torn line in the middle": `{"op":"order","id":1,"cus` + "\n" + `{"op":"status","id":1,"status":"Complete"}`,
+		"unknown status":          `{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Spilled"}`,
+		"not on the menu":         `{"op":"order","id":1,"customer":"a","item":"Tea","status":"Ordered"}`,
+		"never placed":            `{"op":"status","id":1,"status":"Grinding"}`,
+	} {
+		_, err := RecoverOrders(strings.NewReader(journal), menu)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRequeueRecoveredOrders(t *testing.T) {
+	at := time.Now().Format(time.RFC3339Nano)
+	recovered := strings.Join([]string{
+		`{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"status","id":1,"status":"Grinding","at":"` + at + `"}`,
+		`{"op":"order","id":2,"customer":"b","item":"Regular Coffee","status":"Ordered","at":"` + at + `"}`,
+		`{"op":"status","id":2,"status":"Brewing","beans_grams":15,"at":"` + at + `"}`,
+	}, "\n")
+	orders, err := RecoverOrders(strings.NewReader(recovered), Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+
+	journal := &bytes.Buffer{}
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		2, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers(),
+		WithJournal(NewJournal(journal)))
+	for _, order := range orders {
+		assert.True(t, shop.Requeue(order))
+	}
+	for _, order := range orders {
+		order.Wait()
+		assert.Equal(t, Complete, order.GetStatus())
+		assert.NotNil(t, order.Coffee())
+	}
+	shop.Close()
+	assert.False(t, shop.Requeue(orders[0]))
+
+	// the order that was brewing skipped the grinder
+	statuses := []OrderStatus{}
+	for _, change := range orders[1].Timeline() {
+		statuses = append(statuses, change.Status)
+	}
+	assert.NotContains(t, statuses[2:], Grinding)
+	assert.Contains(t, statuses[2:], Brewing)
+
+	// the orders are finished in the new journal
+	left, err := RecoverOrders(strings.NewReader(recovered+"\n"+journal.String()), Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Empty(t, left)
+}
+
+func TestOpenJournalFileCutsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	good := `{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Ordered","at":"` + time.Now().Format(time.RFC3339Nano) + `"}` + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(good+`{"op":"sta`), 0644))
+
+	journal, orders, err := OpenJournalFile(path, Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	journal.write(journal.statusRecord(orders[0]))
+	assert.NoError(t, journal.Close())
+
+	// the torn line is gone and the new record follows the good one
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, strings.TrimSpace(good), lines[0])
+	assert.Contains(t, lines[1], `"op":"status"`)
+}
+
+func TestOpenJournalFileEndsTheLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	good := `{"op":"order","id":1,"customer":"a","item":"Regular Coffee","status":"Ordered","at":"` + time.Now().Format(time.RFC3339Nano) + `"}`
+	assert.NoError(t, os.WriteFile(path, []byte(good), 0644))
+
+	journal, orders, err := OpenJournalFile(path, Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	journal.write(journal.statusRecord(orders[0]))
+	assert.NoError(t, journal.Close())
+
+	// the new record is on its own line and the journal still reads
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(contents), "\x00")
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, good, lines[0])
+
+	journal, orders, err = OpenJournalFile(path, Menu{getTestMenuItem()})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.NoError(t, journal.Close())
+}
+
+// TestJournalCrash kills a shop journaling to a file at random points and
+// checks every order placed before the crash is finished after recovery
+func TestJournalCrash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a shop in another process")
+	}
+
+	seed := time.Now().UnixNano()
+	random := rand.New(rand.NewSource(seed))
+	menu := Menu{getTestMenuItem()}
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(t.TempDir(), "journal")
+		child := exec.Command(os.Args[0], "-test.run=^TestJournalCrashHelper$")
+		child.Env = append(os.Environ(), crashJournalEnv+"="+path)
+		assert.NoError(t, child.Start())
+		time.Sleep(time.Duration(random.Int63n(int64(150 * time.Millisecond))))
+		assert.NoError(t, child.Process.Kill())
+		child.Wait()
+
+		journal, orders, err := OpenJournalFile(path, menu)
+		if !assert.NoError(t, err, "seed %d", seed) {
+			continue
+		}
+		shop := NewCoffeeShop(menu,
+			1, // ordering kiosk count
+			2, // barista count
+			2, // max orders per barista
+			getTestGrinders(),
+			getTestBrewers(),
+			WithJournal(journal))
+		for _, order := range orders {
+			assert.True(t, shop.Requeue(order))
+		}
+		for _, order := range orders {
+			order.Wait()
+			assert.Equal(t, Complete, order.GetStatus(), "seed %d order %d", seed, order.ID)
+		}
+		shop.Close()
+		assert.NoError(t, journal.Close())
+
+		// nothing is left after the recovered orders are finished
+		file, err := os.Open(path)
+		assert.NoError(t, err)
+		left, err := RecoverOrders(file, menu)
+		file.Close()
+		assert.NoError(t, err, "seed %d", seed)
+		assert.Empty(t, left, "seed %d", seed)
+	}
+}
+
+// TestJournalCrashHelper is the shop TestJournalCrash kills, it only
+// runs in the child process
+func TestJournalCrashHelper(t *testing.T) {
+	path := os.Getenv(crashJournalEnv)
+	if path == "" {
+		t.Skip("run by TestJournalCrash")
+	}
+
+	menu := Menu{getTestMenuItem()}
+	journal, _, err := OpenJournalFile(path, menu)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	shop := NewCoffeeShop(menu,
+		1, // ordering kiosk count
+		1, // barista count
+		2, // max orders per barista
+		NewGrinderPool(NewGrinder(10)),
+		NewBrewerPool(NewBrewer(20)),
+		WithJournal(journal))
+
+	for i := 0; i < crashOrderCount; i++ {
+		kiosk := shop.WaitForOrderingKiosk()
+		kiosk.CreateOrder(fmt.Sprintf("Customer-%d", i), getTestMenuItem())
+		shop.LeaveOrderingKiosk(kiosk)
+	}
+
+	// wait to be killed
+	time.Sleep(time.Minute)
+}
@@ -12,7 +12,7 @@ func TestKiosk(t *testing.T) {
 
 	// kiosk defaults to valid, it gets marked invalid
 	// when added to a pool, and valid when taken out
-	k := newOrderingKiosk(orders)
+	k := newOrderingKiosk(orders, nil)
 
 	expectedOrder := k.CreateOrder("name", MenuItem{Name: "test"})
 
@@ -29,7 +29,7 @@ func TestInvalidKiosk(t *testing.T) {
 	// kiosk defaults to valid, set this to invalid
 	// normall set to invalid after an order when it's returned
 	// to the kiosk pool for the next customer to get an use
-	k := newOrderingKiosk(orders)
+	k := newOrderingKiosk(orders, nil)
 	k.setValidity(false)
 
 	// make sure it can't make an order when invalid
@@ -37,3 +37,18 @@ func TestInvalidKiosk(t *testing.T) {
 
 	assert.Nil(t, nilOrder)
 }
+
+func TestRemoveKiosk(t *testing.T) {
+	kp := NewKioskPool()
+	k1 := newOrderingKiosk(make(OrderChannel, 1), nil)
+	k2 := newOrderingKiosk(make(OrderChannel, 1), nil)
+	kp.AddKiosk(k1)
+
+	// the kiosk in use is closed when the customer leaves it
+	inUse := kp.GetKiosk()
+	kp.RemoveKiosk()
+	kp.AddKiosk(inUse)
+	kp.AddKiosk(k2)
+
+	assert.Equal(t, k2, kp.GetKiosk())
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the upper bounds of the stage histograms in simulated seconds
+var stageBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600}
+
+// the stage an order is in for each status, the time an order spends
+// in a status is recorded for its stage when it moves on
+var statusStages = map[OrderStatus]string{
+	Ordered:      "barista_wait",
+	ReadyToGrind: "grinder_wait",
+	Grinding:     "grind",
+	ReadyToBrew:  "brewer_wait",
+	Brewing:      "brew",
+}
+
+// the stage from placing an order to it being complete, when
+// the coffee's handed over whether or not anyone picks it up
+const totalStage = "total"
+
+type histogram struct {
+	// counts for each bucket, not cumulative
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(value float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(stageBuckets))
+	}
+	for i, bound := range stageBuckets {
+		if value <= bound {
+			h.counts[i] += 1
+			break
+		}
+	}
+	h.count += 1
+	h.sum += value
+}
+
+// shopMetrics counts the orders in each status and how long they spend
+// in each stage, what's in the pools is looked at when it's written
+type shopMetrics struct {
+	lock   *sync.Mutex
+	orders map[OrderStatus]int
+	stages map[string]*histogram
+}
+
+func newShopMetrics() *shopMetrics {
+	return &shopMetrics{
+		lock:   &sync.Mutex{},
+		orders: map[OrderStatus]int{},
+		stages: map[string]*histogram{},
+	}
+}
+
+// placed counts a new order, or one put back in line after a restart.
+// It's safe on nil metrics for orders outside a shop.
+func (sm *shopMetrics) placed(status OrderStatus) {
+	if sm == nil {
+		return
+	}
+
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.orders[status] += 1
+}
+
+// statusChanged moves an order to its new status, spent is the time in
+// the status it left and total the time since it was ordered
+func (sm *shopMetrics) statusChanged(from OrderStatus, to OrderStatus, spent time.Duration, total time.Duration) {
+	if sm == nil {
+		return
+	}
+
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.orders[from] -= 1
+	sm.orders[to] += 1
+	if stage, found := statusStages[from]; found && to != Cancelled {
+		sm.observe(stage, spent)
+	}
+	if to == Complete {
+		sm.observe(totalStage, total)
+	}
+}
+
+func (sm *shopMetrics) observe(stage string, d time.Duration) {
+	h, found := sm.stages[stage]
+	if !found {
+		h = &histogram{}
+		sm.stages[stage] = h
+	}
+	h.observe(SimSeconds(d))
+}
+
+// metricsWriter writes the Prometheus text format, keeping the
+// first error so the writes don't all need checking
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (mw *metricsWriter) family(name string, kind string, help string) {
+	mw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one value, labels are name and value pairs
+func (mw *metricsWriter) sample(name string, value float64, labels ...string) {
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	mw.printf("%s %g\n", name, value)
+}
+
+func (mw *metricsWriter) printf(format string, args ...interface{}) {
+	if mw.err == nil {
+		_, mw.err = fmt.Fprintf(mw.w, format, args...)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricName is a status as it's used in a label, like ready_to_grind
+func metricName(s fmt.Stringer) string {
+	return strings.ReplaceAll(strings.ToLower(s.String()), " ", "_")
+}
+
+// copy is the counts and histograms right now, so they're written
+// without holding the lock the orders need to move along
+func (sm *shopMetrics) copy() (map[OrderStatus]int, map[string]histogram) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	orders := map[OrderStatus]int{}
+	for status, count := range sm.orders {
+		orders[status] = count
+	}
+	stages := map[string]histogram{}
+	for stage, h := range sm.stages {
+		stages[stage] = histogram{counts: append([]uint64{}, h.counts...), count: h.count, sum: h.sum}
+	}
+
+	return orders, stages
+}
+
+func (sm *shopMetrics) write(mw *metricsWriter) {
+	orders, histograms := sm.copy()
+
+	mw.family("coffeeshop_orders", "gauge", "Orders placed at the kiosks in each status.")
+	for status := Ordered; status <= Cancelled; status++ {
+		mw.sample("coffeeshop_orders", float64(orders[status]), "status", metricName(status))
+	}
+
+	stages := make([]string, 0, len(histograms))
+	for stage := range histograms {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+
+	name := "coffeeshop_order_stage_seconds"
+	mw.family(name, "histogram", "Simulated seconds orders spent in each stage.")
+	for _, stage := range stages {
+		h := histograms[stage]
+		cumulative := uint64(0)
+		for i, bound := range stageBuckets {
+			cumulative += h.counts[i]
+			mw.sample(name+"_bucket", float64(cumulative), "stage", stage, "le", fmt.Sprintf("%g", bound))
+		}
+		mw.sample(name+"_bucket", float64(h.count), "stage", stage, "le", "+Inf")
+		mw.sample(name+"_sum", h.sum, "stage", stage)
+		mw.sample(name+"_count", float64(h.count), "stage", stage)
+	}
+}
+
+// WriteMetrics writes the shop's metrics in the Prometheus text format,
+// times are in simulated seconds
+func (cs *coffeeShop) WriteMetrics(w io.Writer) error {
+	mw := &metricsWriter{w: bufio.NewWriter(w)}
+
+	cs.metrics.write(mw)
+
+	mw.family("coffeeshop_pool_available", "gauge", "Grinders, brewer slots and kiosks free to use.")
+	mw.sample("coffeeshop_pool_available", float64(cs.grinders.Available()), "pool", "grinders")
+	mw.sample("coffeeshop_pool_available", float64(cs.brewers.Available()), "pool", "brewers")
+	mw.sample("coffeeshop_pool_available", float64(cs.kiosks.Available()), "pool", "kiosks")
+	mw.family("coffeeshop_pool_waiting", "gauge", "Orders waiting on a grinder or brewer and customers waiting on a kiosk.")
+	mw.sample("coffeeshop_pool_waiting", float64(cs.grinders.Waiting()), "pool", "grinders")
+	mw.sample("coffeeshop_pool_waiting", float64(cs.brewers.Waiting()), "pool", "brewers")
+	mw.sample("coffeeshop_pool_waiting", float64(cs.kiosks.Waiting()), "pool", "kiosks")
+
+	// the staff lock isn't held while writing, a slow
+	// reader would keep baristas from being added
+	cs.staffLock.Lock()
+	baristas := append([]*barista{}, cs.baristas...)
+	cs.staffLock.Unlock()
+	mw.family("coffeeshop_barista_active_orders", "gauge", "Orders each barista is working on.")
+	for _, b := range baristas {
+		mw.sample("coffeeshop_barista_active_orders", float64(b.getCurrentOrderCount()), "barista", b.Name)
+	}
+
+	// a brewer is busy for the time of all its slots
+	name := "coffeeshop_equipment_busy_seconds_total"
+	mw.family(name, "counter", "Simulated seconds each grinder and brewer was in use.")
+	for _, usage := range cs.grinders.Utilization() {
+		mw.sample(name, SimSeconds(usage.Busy), "kind", "grinder", "machine", usage.Grinder)
+	}
+	brewers := []string{}
+	busy := map[string]time.Duration{}
+	for _, slot := range cs.brewers.Utilization() {
+		if _, found := busy[slot.Brewer]; !found {
+			brewers = append(brewers, slot.Brewer)
+		}
+		busy[slot.Brewer] += slot.Busy
+	}
+	for _, brewer := range brewers {
+		mw.sample(name, SimSeconds(busy[brewer]), "kind", "brewer", "machine", brewer)
+	}
+
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.w.Flush()
+}
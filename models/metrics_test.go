@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+	shop.Close()
+
+	metrics := &bytes.Buffer{}
+	assert.NoError(t, shop.WriteMetrics(metrics))
+	text := metrics.String()
+
+	assert.Contains(t, text, "# TYPE coffeeshop_orders gauge\n")
+	assert.Contains(t, text, `coffeeshop_orders{status="complete"} 1`+"\n")
+	assert.Contains(t, text, `coffeeshop_orders{status="ordered"} 0`+"\n")
+	assert.Contains(t, text, "# TYPE coffeeshop_order_stage_seconds histogram\n")
+	for _, stage := range []string{"barista_wait", "grinder_wait", "grind", "brewer_wait", "brew", "total"} {
+		assert.Contains(t, text, `coffeeshop_order_stage_seconds_bucket{stage="`+stage+`",le="+Inf"} 1`+"\n")
+		assert.Contains(t, text, `coffeeshop_order_stage_seconds_count{stage="`+stage+`"} 1`+"\n")
+	}
+	assert.Contains(t, text, `coffeeshop_pool_available{pool="grinders"} 1`+"\n")
+	assert.Contains(t, text, `coffeeshop_pool_waiting{pool="kiosks"} 0`+"\n")
+	assert.Contains(t, text, `coffeeshop_barista_active_orders{barista="Barista-0"} 0`+"\n")
+	assert.Contains(t, text, `coffeeshop_equipment_busy_seconds_total{kind="grinder",machine="Grinder-0"}`)
+	assert.Contains(t, text, `coffeeshop_equipment_busy_seconds_total{kind="brewer",machine="Drip-0"}`)
+}
+
+func TestMetricsCancelledOrder(t *testing.T) {
+	metrics := newShopMetrics()
+	order := NewOrder("test", getTestMenuItem())
+	order.metrics = metrics
+	metrics.placed(Ordered)
+
+	order.setStatus(ReadyToGrind)
+	order.Cancel()
+
+	// the time waiting on a barista counts, cancelling doesn't
+	assert.Equal(t, 0, metrics.orders[Ordered])
+	assert.Equal(t, 0, metrics.orders[ReadyToGrind])
+	assert.Equal(t, 1, metrics.orders[Cancelled])
+	assert.Equal(t, uint64(1), metrics.stages["barista_wait"].count)
+	assert.Nil(t, metrics.stages["grinder_wait"])
+}
+
+func TestSlowReaderDoesntHoldUpOrders(t *testing.T) {
+	metrics := newShopMetrics()
+	metrics.placed(Ordered)
+	reader, writer := io.Pipe()
+	written := make(chan error)
+	go func() {
+		mw := &metricsWriter{w: bufio.NewWriterSize(writer, 16)}
+		metrics.write(mw)
+		written <- mw.w.Flush()
+	}()
+
+	// the reader takes a little and stops, the orders still move along
+	_, err := reader.Read(make([]byte, 16))
+	assert.NoError(t, err)
+	moved := make(chan bool)
+	go func() {
+		metrics.statusChanged(Ordered, ReadyToGrind, time.Second, time.Second)
+		moved <- true
+	}()
+	select {
+	case <-moved:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the order waited on the reader")
+	}
+
+	go io.Copy(io.Discard, reader)
+	assert.NoError(t, <-written)
+}
+
+func TestLabelEscaping(t *testing.T) {
+	metrics := &bytes.Buffer{}
+	mw := &metricsWriter{w: bufio.NewWriter(metrics)}
+	mw.sample("test", 1.5, "name", "say \"hi\"\\\n")
+	assert.NoError(t, mw.w.Flush())
+	assert.Equal(t, `test{name="say \"hi\"\\\n"} 1.5`+"\n", metrics.String())
+}
@@ -1,23 +1,188 @@
 package models
 
-import "sync"
+import (
+	"blreynolds4/coffeeshop/units"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RoastLevel int
+
+const (
+	LightRoast RoastLevel = iota
+	MediumRoast
+	DarkRoast
+)
+
+func (r RoastLevel) String() string {
+	switch r {
+	case LightRoast:
+		return "Light"
+	case MediumRoast:
+		return "Medium"
+	case DarkRoast:
+		return "Dark"
+	}
+
+	return "unknown roast"
+}
+
+// BeanVariety identifies a kind of bean so grinders know
+// when they need to be purged before grinding something else
+type BeanVariety struct {
+	Origin string
+	Roast  RoastLevel
+	Decaf  bool
+}
+
+func (v BeanVariety) String() string {
+	name := v.Origin + " " + v.Roast.String()
+	if v.Decaf {
+		name += " Decaf"
+	}
+
+	return name
+}
 
 type Beans struct {
-	weightGrams int
+	weight  units.Grams
+	variety BeanVariety
+}
+
+func NewBeans(weight units.Grams, variety BeanVariety) Beans {
+	return Beans{
+		weight:  weight,
+		variety: variety,
+	}
+}
+
+func (b Beans) Weight() units.Grams {
+	return b.weight
+}
+
+func (b Beans) Variety() BeanVariety {
+	return b.variety
 }
 
 type Coffee struct {
-	sizeOunces int
+	volume   units.Milliliters
+	method   BrewMethod
+	body     Body
+	variety  BeanVariety
+	ratio    units.Ratio
+	brewer   Brewer
+	brewedAt time.Time
+	// the barista fills in the item the coffee was made for
+	// and how good it is
+	item    MenuItem
+	quality Quality
+}
+
+// NewCoffee is the coffee a brewer made from the beans, the
+// method and body come from the brewer
+func NewCoffee(volume units.Milliliters, beans Beans, brewer Brewer) *Coffee {
+	return &Coffee{
+		volume:   volume,
+		method:   brewer.Method(),
+		body:     brewer.Method().Body(),
+		variety:  beans.variety,
+		ratio:    units.RatioOf(volume, beans.weight),
+		brewer:   brewer,
+		brewedAt: time.Now(),
+	}
+}
+
+func (c *Coffee) Volume() units.Milliliters {
+	return c.volume
+}
+
+func (c *Coffee) Method() BrewMethod {
+	return c.method
+}
+
+func (c *Coffee) Body() Body {
+	return c.body
+}
+
+func (c *Coffee) Variety() BeanVariety {
+	return c.variety
+}
+
+// Ratio is the strength the coffee was brewed at
+func (c *Coffee) Ratio() units.Ratio {
+	return c.ratio
+}
+
+func (c *Coffee) Brewer() Brewer {
+	return c.brewer
+}
+
+func (c *Coffee) BrewedAt() time.Time {
+	return c.brewedAt
+}
+
+// Item is the menu item the coffee was made for
+func (c *Coffee) Item() MenuItem {
+	return c.item
+}
+
+// Quality is scored when the coffee is handed to the customer
+func (c *Coffee) Quality() Quality {
+	return c.quality
 }
 
 type MenuItem struct {
 	Name        string
-	Size        int
-	CoffeeRatio int
+	Size        units.Milliliters
+	CoffeeRatio units.Ratio
+	Variety     BeanVariety
+	// the brewing methods that can make the item, empty means any
+	Methods []BrewMethod
+}
+
+func (mi MenuItem) BrewableWith(method BrewMethod) bool {
+	if len(mi.Methods) == 0 {
+		return true
+	}
+
+	for _, m := range mi.Methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
 }
 
 type Menu []MenuItem
 
+// Item is the item on the menu with the name
+func (m Menu) Item(name string) (MenuItem, bool) {
+	for _, item := range m {
+		if item.Name == name {
+			return item, true
+		}
+	}
+
+	return MenuItem{}, false
+}
+
+// Brewable returns the items that can be made with the methods
+func (m Menu) Brewable(methods ...BrewMethod) Menu {
+	result := Menu{}
+	for _, item := range m {
+		for _, method := range methods {
+			if item.BrewableWith(method) {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
 type OrderStatus int
 
 const (
@@ -27,6 +192,7 @@ const (
 	ReadyToBrew
 	Brewing
 	Complete
+	Cancelled
 )
 
 func (s OrderStatus) String() string {
@@ -43,29 +209,194 @@ func (s OrderStatus) String() string {
 		return "Brewing"
 	case Complete:
 		return "Complete"
+	case Cancelled:
+		return "Cancelled"
 	}
 
 	return "unknown order status"
 }
 
+// StatusChange is when an order moved to a status
+type StatusChange struct {
+	Status OrderStatus
+	At     time.Time
+}
+
+// every order gets the next ID
+var lastOrderID int64
+
 type Order struct {
+	ID          int64
 	Customer    string
 	Item        MenuItem
 	Status      OrderStatus
 	GroundBeans Beans
 	freshCoffee *Coffee
 	doneFlag    *sync.Cond
+	orderedAt   time.Time
+	// when the beans for the order finished grinding
+	groundAt time.Time
+	// when the first wrong drink for the order was caught
+	remadeAt time.Time
+	// cups to brew when the order fills a carafe instead of a cup
+	batchCups int
+	// the shop's stats, so the quality is recorded when it's served
+	stats *statsRecorder
+	// every status the order has been through, guarded by the done flag
+	timeline  []StatusChange
+	cancelled bool
+	// the shop's events, status changes are published to it
+	events *eventBus
+	// the shop's metrics for orders placed at a kiosk
+	metrics *shopMetrics
+	// the spans of the order when the shop is traced, serving
+	// is open from pouring till the customer has it
+	trace   *orderTrace
+	serving *activeSpan
+	// the barista working on it, guarded by the done flag
+	barista string
+	// the shop's journal for orders placed at a kiosk
+	journal *Journal
+	// the shop's orders, a finished order is moved out of the open ones
+	book *orderBook
 }
 
 func NewOrder(cust string, item MenuItem) *Order {
+	now := time.Now()
 	return &Order{
-		Customer: cust,
-		Item:     item,
-		Status:   Ordered,
-		doneFlag: sync.NewCond(&sync.Mutex{}),
+		ID:        atomic.AddInt64(&lastOrderID, 1),
+		Customer:  cust,
+		Item:      item,
+		Status:    Ordered,
+		doneFlag:  sync.NewCond(&sync.Mutex{}),
+		orderedAt: now,
+		timeline:  []StatusChange{{Status: Ordered, At: now}},
 	}
 }
 
+// setStatus moves the order along and adds it to the timeline.
+// A cancelled order stays cancelled.
+func (o *Order) setStatus(status OrderStatus) {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	if o.cancelled || o.Status == status {
+		return
+	}
+	o.changeStatus(status)
+}
+
+// changeStatus records the new status, the done flag lock must be held
+func (o *Order) changeStatus(status OrderStatus) {
+	now := time.Now()
+	last := o.timeline[len(o.timeline)-1]
+	o.metrics.statusChanged(o.Status, status, now.Sub(last.At), now.Sub(o.orderedAt))
+
+	o.Status = status
+	o.timeline = append(o.timeline, StatusChange{Status: status, At: now})
+	o.journal.statusChanged(o)
+	if status == Complete || status == Cancelled {
+		o.book.finish(o)
+	}
+	o.publishStatus()
+}
+
+// publishStatus sends the current status to the shop's events,
+// the done flag lock must be held
+func (o *Order) publishStatus() {
+	o.events.publish(ShopEvent{
+		Kind:     StatusEvent,
+		OrderID:  o.ID,
+		Customer: o.Customer,
+		Status:   o.Status,
+	})
+}
+
+// GetStatus is the status safe to read while the order is being made
+func (o *Order) GetStatus() OrderStatus {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	return o.Status
+}
+
+func (o *Order) Timeline() []StatusChange {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	return append([]StatusChange{}, o.timeline...)
+}
+
+// Coffee is the coffee made for the order without picking it
+// up, nil if it isn't ready
+func (o *Order) Coffee() *Coffee {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	return o.freshCoffee
+}
+
+// Cancel stops the order wherever it is.  It's false if the
+// coffee was already made or the order was already cancelled.
+func (o *Order) Cancel() bool {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	if o.cancelled || o.freshCoffee != nil {
+		return false
+	}
+
+	o.cancelled = true
+	o.changeStatus(Cancelled)
+	o.trace.finish(Cancelled)
+	// let anyone waiting on the coffee go
+	o.doneFlag.Broadcast()
+	return true
+}
+
+// workedBy records the barista working on the order
+func (o *Order) workedBy(barista string) {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	o.barista = barista
+}
+
+// snapshot is the order as it is right now and who's working on it
+func (o *Order) snapshot() (OrderSnapshot, string) {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	return OrderSnapshot{ID: o.ID, Customer: o.Customer, Item: o.Item.Name, Status: o.Status}, o.barista
+}
+
+func (o *Order) isCancelled() bool {
+	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
+
+	return o.cancelled
+}
+
+// newBatchOrder is the barista's own order to fill a carafe
+func newBatchOrder(item MenuItem, cups int) *Order {
+	result := NewOrder("carafe of "+item.Name, item)
+	result.batchCups = cups
+	return result
+}
+
+func (o *Order) isBatch() bool {
+	return o.batchCups > 0
+}
+
+// volume is how much coffee to make for the order
+func (o *Order) volume() units.Milliliters {
+	if o.isBatch() {
+		return o.Item.Size * units.Milliliters(o.batchCups)
+	}
+
+	return o.Item.Size
+}
+
 type OrderEvent interface {
 	GetOrder() *Order
 }
@@ -78,6 +409,7 @@ type GrinderAvailableEvent interface {
 type GrindCompleteEvent interface {
 	OrderEvent
 	GetBeans() Beans
+	GetGroundAt() time.Time
 }
 
 type BrewerAvailableEvent interface {
@@ -101,7 +433,8 @@ type grinderAvailable struct {
 
 type grindComplete struct {
 	orderEvent
-	beans Beans
+	beans    Beans
+	groundAt time.Time
 }
 type brewerAvailable struct {
 	orderEvent
@@ -124,6 +457,7 @@ func NewGrindCompleteEvent(o *Order, b Beans) GrindCompleteEvent {
 	return &grindComplete{
 		orderEvent: orderEvent{order: o},
 		beans:      b,
+		groundAt:   time.Now(),
 	}
 }
 
@@ -153,6 +487,10 @@ func (gc *grindComplete) GetBeans() Beans {
 	return gc.beans
 }
 
+func (gc *grindComplete) GetGroundAt() time.Time {
+	return gc.groundAt
+}
+
 func (ga *brewerAvailable) GetBrewer() Brewer {
 	return ga.brewer
 }
@@ -181,20 +519,44 @@ func isCoffeeComplete(e OrderEvent) bool {
 	return isCc
 }
 
-func (o *Order) NotifyCustomer(c *Coffee) {
+// NotifyCustomer hands over the coffee and completes the order in one
+// step, so a complete order always has its coffee and can't be cancelled.
+// It's false if the order was cancelled first.
+func (o *Order) NotifyCustomer(c *Coffee) bool {
 	// release the wait with the coffee
 	o.doneFlag.L.Lock()
 	defer o.doneFlag.L.Unlock()
 
+	if o.cancelled {
+		return false
+	}
+	// the coffee has been cooling since it was brewed, it's
+	// scored as it's handed over whether anyone waits on it or not
+	c.quality = c.quality.served(time.Since(c.brewedAt))
+	if o.stats != nil {
+		o.stats.coffeeServed(c)
+	}
 	o.freshCoffee = c
+	if o.stats != nil {
+		o.stats.orderServed(o)
+	}
+	o.serving.end()
+	o.trace.finish(Complete)
+	// it's served in the stats before anyone hears it's complete
+	if o.Status != Complete {
+		o.changeStatus(Complete)
+	}
 
 	o.doneFlag.Broadcast()
+	return true
 }
 
+// Wait for the coffee, nil if the order was cancelled
 func (o *Order) Wait() *Coffee {
 	o.doneFlag.L.Lock()
+	defer o.doneFlag.L.Unlock()
 
-	if o.freshCoffee == nil {
+	for o.freshCoffee == nil && !o.cancelled {
 		// wait for the coffee  to be Completed
 		o.doneFlag.Wait()
 	}
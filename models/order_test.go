@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrewableMenu(t *testing.T) {
+	anyMethod := MenuItem{Name: "any"}
+	pressOnly := MenuItem{Name: "press", Methods: []BrewMethod{FrenchPress}}
+	menu := Menu{anyMethod, pressOnly}
+
+	assert.True(t, anyMethod.BrewableWith(AeroPress))
+	assert.False(t, pressOnly.BrewableWith(Drip))
+
+	assert.Equal(t, Menu{anyMethod}, menu.Brewable(Drip))
+	assert.Equal(t, menu, menu.Brewable(Drip, FrenchPress))
+}
+
+func TestOrderTimeline(t *testing.T) {
+	order := NewOrder("customer", getTestMenuItem())
+	assert.Greater(t, NewOrder("next", getTestMenuItem()).ID, order.ID)
+
+	order.setStatus(ReadyToGrind)
+	order.setStatus(ReadyToGrind)
+	order.setStatus(Grinding)
+
+	timeline := order.Timeline()
+	assert.Len(t, timeline, 3)
+	assert.Equal(t, Ordered, timeline[0].Status)
+	assert.Equal(t, Grinding, timeline[2].Status)
+	assert.Equal(t, Grinding, order.GetStatus())
+	assert.Nil(t, order.Coffee())
+}
+
+func TestCancelOrder(t *testing.T) {
+	order := NewOrder("customer", getTestMenuItem())
+
+	go func() {
+		assert.True(t, order.Cancel())
+	}()
+
+	// the wait is let go without coffee
+	assert.Nil(t, order.Wait())
+	assert.False(t, order.Cancel())
+	assert.Equal(t, Cancelled, order.GetStatus())
+
+	// nothing moves it along or fills it after that
+	order.setStatus(Brewing)
+	assert.False(t, order.NotifyCustomer(&Coffee{}))
+	assert.Equal(t, Cancelled, order.GetStatus())
+	assert.Nil(t, order.Coffee())
+
+	// a finished order can't be cancelled
+	done := NewOrder("customer", getTestMenuItem())
+	assert.True(t, done.NotifyCustomer(&Coffee{}))
+	assert.False(t, done.Cancel())
+}
+
+func TestCompleteHasTheCoffee(t *testing.T) {
+	order := NewOrder("customer", getTestMenuItem())
+	coffee := &Coffee{}
+
+	// the order is complete the moment the coffee's handed over
+	assert.True(t, order.NotifyCustomer(coffee))
+	assert.Equal(t, Complete, order.GetStatus())
+	assert.Equal(t, coffee, order.Coffee())
+	assert.False(t, order.Cancel())
+
+	statuses := []OrderStatus{}
+	for _, change := range order.Timeline() {
+		statuses = append(statuses, change.Status)
+	}
+	assert.Equal(t, []OrderStatus{Ordered, Complete}, statuses)
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// the hands on time for a trained barista at normal speed,
+	// weighing out the beans before the grind and pouring the
+	// coffee once it's brewed
+	doseSeconds = 15
+	pourSeconds = 10
+
+	// the drink has to be this close to the menu item to pass QA
+	qaTolerance = 0.05
+)
+
+// Station is a part of the shop a barista can be trained on
+type Station int
+
+const (
+	GrindStation Station = iota
+	DripStation
+	PourOverStation
+	FrenchPressStation
+	AeroPressStation
+	BatchStation
+)
+
+func (s Station) String() string {
+	switch s {
+	case GrindStation:
+		return "Grind"
+	case DripStation:
+		return "Drip"
+	case PourOverStation:
+		return "Pour Over"
+	case FrenchPressStation:
+		return "French Press"
+	case AeroPressStation:
+		return "AeroPress"
+	case BatchStation:
+		return "Batch"
+	}
+
+	return "unknown station"
+}
+
+// stationFor is where a brew method is made
+func stationFor(method BrewMethod) Station {
+	switch method {
+	case PourOver:
+		return PourOverStation
+	case FrenchPress:
+		return FrenchPressStation
+	case AeroPress:
+		return AeroPressStation
+	case BatchDrip:
+		return BatchStation
+	}
+
+	return DripStation
+}
+
+// brewMethodFor is the brew method made at a station
+func brewMethodFor(station Station) (BrewMethod, bool) {
+	switch station {
+	case DripStation:
+		return Drip, true
+	case PourOverStation:
+		return PourOver, true
+	case FrenchPressStation:
+		return FrenchPress, true
+	case AeroPressStation:
+		return AeroPress, true
+	case BatchStation:
+		return BatchDrip, true
+	}
+
+	return 0, false
+}
+
+// BaristaProfile is how skilled a barista is.  At a station they
+// aren't trained on they take twice as long and make twice the mistakes.
+type BaristaProfile struct {
+	Name string
+	// Speed multiplies how fast the hands on steps go, 2 is twice as fast
+	Speed float64
+	// ErrorRate is the chance of getting a step wrong and making the wrong drink
+	ErrorRate float64
+	// Stations the barista is trained on, empty is trained on all of them
+	Stations []Station
+}
+
+func DefaultBaristaProfile(name string) BaristaProfile {
+	return BaristaProfile{
+		Name:  name,
+		Speed: 1,
+	}
+}
+
+func (p BaristaProfile) TrainedOn(station Station) bool {
+	if len(p.Stations) == 0 {
+		return true
+	}
+
+	for _, s := range p.Stations {
+		if s == station {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DoseTime is how long the barista takes to weigh out the beans,
+// they hold the grinder while they do
+func (p BaristaProfile) DoseTime() time.Duration {
+	return p.handsOnTime(doseSeconds, GrindStation)
+}
+
+// handsOnTime is how long the barista takes for a step at the station
+func (p BaristaProfile) handsOnTime(seconds int, station Station) time.Duration {
+	speed := p.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	if !p.TrainedOn(station) {
+		speed /= 2
+	}
+
+	return time.Duration(float64(time.Duration(seconds)*SimSecond) / speed)
+}
+
+func (p BaristaProfile) errorRateAt(station Station) float64 {
+	if !p.TrainedOn(station) {
+		return math.Min(1, p.ErrorRate*2)
+	}
+
+	return p.ErrorRate
+}
+
+// trainedItem limits the brew methods for the item to the ones the barista
+// is trained on.  If they aren't trained on any of them the item is
+// returned as is, someone has to make it.
+func (p BaristaProfile) trainedItem(item MenuItem) MenuItem {
+	trained := []BrewMethod{}
+	for _, station := range p.Stations {
+		method, isBrewing := brewMethodFor(station)
+		if isBrewing && item.BrewableWith(method) {
+			trained = append(trained, method)
+		}
+	}
+
+	if len(p.Stations) == 0 || len(trained) == 0 {
+		return item
+	}
+
+	item.Methods = trained
+	return item
+}
+
+// passesQA is true if the coffee is the drink on the order
+func passesQA(order *Order, coffee *Coffee) bool {
+	return coffee.variety == order.Item.Variety &&
+		within(float64(coffee.volume), float64(order.volume())) &&
+		within(float64(coffee.ratio), float64(order.Item.CoffeeRatio))
+}
+
+func within(actual float64, expected float64) bool {
+	return math.Abs(actual-expected) <= expected*qaTolerance
+}
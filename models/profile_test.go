@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandsOnTime(t *testing.T) {
+	profile := BaristaProfile{Name: "test", Speed: 2, Stations: []Station{GrindStation}}
+
+	// twice as fast where trained, normal speed where they aren't
+	assert.Equal(t, 5*SimSecond, profile.handsOnTime(10, GrindStation))
+	assert.Equal(t, 10*SimSecond, profile.handsOnTime(10, DripStation))
+
+	// no speed is normal speed
+	assert.Equal(t, 10*SimSecond, BaristaProfile{}.handsOnTime(10, DripStation))
+}
+
+func TestErrorRateAt(t *testing.T) {
+	profile := BaristaProfile{ErrorRate: 0.1, Stations: []Station{GrindStation}}
+	assert.Equal(t, 0.1, profile.errorRateAt(GrindStation))
+	assert.Equal(t, 0.2, profile.errorRateAt(PourOverStation))
+
+	// never more than always
+	profile.ErrorRate = 0.75
+	assert.Equal(t, 1.0, profile.errorRateAt(PourOverStation))
+}
+
+func TestTrainedItem(t *testing.T) {
+	item := getTestMenuItem()
+	item.Methods = []BrewMethod{Drip, PourOver}
+
+	// untrained profiles can make anything
+	assert.Equal(t, item, DefaultBaristaProfile("test").trainedItem(item))
+
+	pourOver := BaristaProfile{Stations: []Station{GrindStation, PourOverStation}}
+	assert.Equal(t, []BrewMethod{PourOver}, pourOver.trainedItem(item).Methods)
+
+	// not trained on any of the item's methods, make it anyway
+	press := BaristaProfile{Stations: []Station{FrenchPressStation}}
+	assert.Equal(t, item.Methods, press.trainedItem(item).Methods)
+}
+
+func TestPassesQA(t *testing.T) {
+	order := NewOrder("customer", getTestMenuItem())
+	beans := NewBeans(order.Item.CoffeeRatio.Coffee(order.Item.Size), order.Item.Variety)
+
+	assert.True(t, passesQA(order, NewCoffee(order.Item.Size, beans, &MockBrewer{})))
+
+	// short pour
+	assert.False(t, passesQA(order, NewCoffee(order.Item.Size*0.75, beans, &MockBrewer{})))
+
+	// short dose makes it too weak
+	weak := NewBeans(beans.Weight()*0.7, order.Item.Variety)
+	assert.False(t, passesQA(order, NewCoffee(order.Item.Size, weak, &MockBrewer{})))
+
+	// wrong beans
+	decaf := order.Item.Variety
+	decaf.Decaf = true
+	wrong := NewBeans(beans.Weight(), decaf)
+	assert.False(t, passesQA(order, NewCoffee(order.Item.Size, wrong, &MockBrewer{})))
+}
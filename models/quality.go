@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"blreynolds4/coffeeshop/units"
+)
+
+const (
+	// grounds lose their aroma quickly, after this many
+	// seconds about a third of the freshness is left
+	groundsStaleSeconds = 300
+
+	// extraction keeps slowing down as it goes, this is the most that
+	// will ever dissolve and the seconds it takes to get about 2/3 of it
+	maxExtractionPercent  = 26.0
+	extractionTimeSeconds = 100
+	// the ratio the extraction above is for, more water extracts more
+	referenceRatio = 16.5
+	// the extraction percent range that tastes right
+	idealExtractionLow  = 18.0
+	idealExtractionHigh = 22.0
+	// how far outside the ideal range before it's undrinkable
+	extractionTolerance = 6.0
+
+	// coffee cools toward the room temperature, losing half
+	// the difference every half life
+	brewedTemperature      = 90.0
+	roomTemperature        = 20.0
+	coolingHalfLifeSeconds = 600
+	// coffee is at its best at this temperature or above and
+	// not worth drinking by the time it cools to the cold one
+	warmTemperature = 60.0
+	coldTemperature = 40.0
+)
+
+// Quality is how good the coffee is when it's handed to the customer
+type Quality struct {
+	// Freshness goes from 1 for grounds brewed right away toward 0
+	Freshness float64
+	// Extraction is the percent of the grounds dissolved in the coffee
+	Extraction float64
+	// Temperature in celsius when it's served
+	Temperature float64
+	// Score is from 0 to 100
+	Score float64
+}
+
+// newQuality rates the brew from how long the grounds sat and
+// how long the brew took at its ratio
+func newQuality(groundsAge time.Duration, brewTime time.Duration, ratio units.Ratio) Quality {
+	extraction := maxExtractionPercent *
+		(1 - math.Exp(-SimSeconds(brewTime)/extractionTimeSeconds)) *
+		math.Sqrt(float64(ratio)/referenceRatio)
+
+	return Quality{
+		Freshness:   math.Exp(-SimSeconds(groundsAge) / groundsStaleSeconds),
+		Extraction:  extraction,
+		Temperature: brewedTemperature,
+	}
+}
+
+// served cools the coffee for the time since it was brewed
+// and scores it
+func (q Quality) served(sinceBrewed time.Duration) Quality {
+	halfLives := SimSeconds(sinceBrewed) / coolingHalfLifeSeconds
+	q.Temperature = roomTemperature + (brewedTemperature-roomTemperature)*math.Pow(0.5, halfLives)
+	q.Score = 100 * q.Freshness * extractionScore(q.Extraction) * temperatureScore(q.Temperature)
+	return q
+}
+
+func extractionScore(extraction float64) float64 {
+	off := 0.0
+	if extraction < idealExtractionLow {
+		off = idealExtractionLow - extraction
+	} else if extraction > idealExtractionHigh {
+		off = extraction - idealExtractionHigh
+	}
+
+	return math.Max(0, 1-off/extractionTolerance)
+}
+
+func temperatureScore(temperature float64) float64 {
+	if temperature >= warmTemperature {
+		return 1
+	}
+
+	return math.Max(0, (temperature-coldTemperature)/(warmTemperature-coldTemperature))
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreshGroundsScoreBetter(t *testing.T) {
+	fresh := newQuality(0, 150*SimSecond, 16.5).served(0)
+	stale := newQuality(600*SimSecond, 150*SimSecond, 16.5).served(0)
+
+	assert.InDelta(t, 1, fresh.Freshness, 0.001)
+	assert.Less(t, stale.Freshness, 0.2)
+	assert.Greater(t, fresh.Score, stale.Score)
+}
+
+func TestExtraction(t *testing.T) {
+	under := newQuality(0, 20*SimSecond, 16.5)
+	ideal := newQuality(0, 150*SimSecond, 16.5)
+	over := newQuality(0, 150*SimSecond, 25)
+
+	assert.Less(t, under.Extraction, idealExtractionLow)
+	assert.InDelta(t, 20, ideal.Extraction, 2)
+	// more water per gram pulls out more
+	assert.Greater(t, over.Extraction, idealExtractionHigh)
+
+	assert.Equal(t, 1.0, extractionScore(ideal.Extraction))
+	assert.Less(t, extractionScore(under.Extraction), 1.0)
+	assert.Less(t, extractionScore(over.Extraction), 1.0)
+	assert.Equal(t, 0.0, extractionScore(0))
+}
+
+func TestCoolingWhileWaiting(t *testing.T) {
+	q := newQuality(0, 150*SimSecond, 16.5)
+
+	hot := q.served(0)
+	assert.Equal(t, brewedTemperature, hot.Temperature)
+	assert.InDelta(t, 100, hot.Score, 0.1)
+
+	// one half life is half way to room temperature
+	halfLife := q.served(coolingHalfLifeSeconds * SimSecond)
+	assert.InDelta(t, 55, halfLife.Temperature, 0.01)
+	assert.Less(t, halfLife.Score, hot.Score)
+
+	cold := q.served(time.Hour)
+	assert.Equal(t, 0.0, cold.Score)
+}
+
+func TestQualityScoredWhenServed(t *testing.T) {
+	stats := newStatsRecorder()
+	order := NewOrder("customer", getTestMenuItem())
+	order.stats = stats
+
+	c := NewCoffee(240, NewBeans(15, getTestVariety()), &MockBrewer{})
+	c.quality = newQuality(0, 150*SimSecond, c.Ratio())
+	order.NotifyCustomer(c)
+
+	// it's scored even when nobody waits on it
+	assert.Len(t, stats.snapshot().QualityScores, 1)
+	assert.Greater(t, order.Coffee().Quality().Score, 90.0)
+
+	// waiting for it doesn't score it again
+	order.Wait()
+	order.Wait()
+	assert.Len(t, stats.snapshot().QualityScores, 1)
+}
@@ -1,95 +1,554 @@
 package models
 
-import "sync"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
 
 type sharedPool[A any] struct {
 	items  []A
 	signal sync.Cond
+	// items to take out of the pool when they come back
+	retiring []func(A) bool
+	// who's waiting on an item, first come first served
+	waiters []*poolWaiter[A]
+}
+
+// poolWaiter is what someone waiting on the pool will take
+type poolWaiter[A any] struct {
+	match  func(A) bool
+	prefer func(A) bool
+}
+
+// pick is the index of the item the waiter takes from the ones not
+// taken, a preferred item over the others, -1 if none match
+func (pw *poolWaiter[A]) pick(items []A, taken []bool) int {
+	found := -1
+	for i, item := range items {
+		if taken[i] || !pw.match(item) {
+			continue
+		}
+		if pw.prefer(item) {
+			return i
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+
+	return found
 }
 
 func (sp *sharedPool[A]) AddToPool(obj A) {
 	sp.signal.L.Lock()
 	defer sp.signal.L.Unlock()
 
+	for i, retire := range sp.retiring {
+		if retire(obj) {
+			sp.retiring = append(sp.retiring[:i], sp.retiring[i+1:]...)
+			return
+		}
+	}
+
 	sp.items = append(sp.items, obj)
-	sp.signal.Signal()
+	// wake everyone, a waiter may not want this item
+	sp.signal.Broadcast()
 }
 
 func (gp *sharedPool[A]) GetFromPool() A {
+	return gp.GetFromPoolMatching(anyItem[A], anyItem[A])
+}
+
+// GetFromPoolMatching waits for an item that matches and takes
+// a preferred item over the others if one is available.  The ones
+// waiting longest pick first, so who gets an item doesn't depend
+// on which waiter wakes up first.
+func (gp *sharedPool[A]) GetFromPoolMatching(match func(A) bool, prefer func(A) bool) A {
 	gp.signal.L.Lock()
 	defer gp.signal.L.Unlock()
 
-	for len(gp.items) == 0 {
+	waiter := &poolWaiter[A]{match: match, prefer: prefer}
+	gp.waiters = append(gp.waiters, waiter)
+	for {
+		if found := gp.pickFor(waiter); found >= 0 {
+			result := gp.items[found]
+			gp.items = append(gp.items[:found], gp.items[found+1:]...)
+			for i, w := range gp.waiters {
+				if w == waiter {
+					gp.waiters = append(gp.waiters[:i], gp.waiters[i+1:]...)
+					break
+				}
+			}
+			return result
+		}
+
 		gp.signal.Wait()
 	}
+}
 
-	result := gp.items[0]
-	gp.items = gp.items[1:]
-	return result
+// pickFor is the item the waiter gets after everyone
+// ahead of them has picked, -1 if there isn't one
+func (gp *sharedPool[A]) pickFor(waiter *poolWaiter[A]) int {
+	taken := make([]bool, len(gp.items))
+	for _, w := range gp.waiters {
+		found := w.pick(gp.items, taken)
+		if w == waiter {
+			return found
+		}
+		if found >= 0 {
+			taken[found] = true
+		}
+	}
+
+	return -1
+}
+
+// RemoveFromPool takes out an item that matches.  If none are in the pool
+// the next one to match is taken out when it's added back.  It's true if
+// the item was taken out right away.
+func (sp *sharedPool[A]) RemoveFromPool(match func(A) bool) bool {
+	sp.signal.L.Lock()
+	defer sp.signal.L.Unlock()
+
+	for i, item := range sp.items {
+		if match(item) {
+			sp.items = append(sp.items[:i], sp.items[i+1:]...)
+			return true
+		}
+	}
+
+	sp.retiring = append(sp.retiring, match)
+	return false
+}
+
+// restoreToPool puts back an item that was removed.  A removal still
+// waiting on it is dropped instead, the item is out in use and comes
+// back to the pool on its own.
+func (sp *sharedPool[A]) restoreToPool(obj A) {
+	sp.signal.L.Lock()
+	for i, retire := range sp.retiring {
+		if retire(obj) {
+			sp.retiring = append(sp.retiring[:i], sp.retiring[i+1:]...)
+			sp.signal.L.Unlock()
+			return
+		}
+	}
+	sp.signal.L.Unlock()
+
+	sp.AddToPool(obj)
+}
+
+// available is how many items are in the pool right now
+func (sp *sharedPool[A]) available() int {
+	sp.signal.L.Lock()
+	defer sp.signal.L.Unlock()
+
+	return len(sp.items)
+}
+
+// waiting is how many are waiting on an item right now
+func (sp *sharedPool[A]) waiting() int {
+	sp.signal.L.Lock()
+	defer sp.signal.L.Unlock()
+
+	return len(sp.waiters)
+}
+
+func anyItem[A any](A) bool {
+	return true
 }
 
 type GrinderPool interface {
 	AddGrinder(Grinder)
 	GetGrinder() Grinder
+	GetGrinderFor(BeanVariety) Grinder
+	// RemoveGrinder takes the grinder out now, or when it's
+	// added back if it's in use
+	RemoveGrinder(Grinder)
+	// RestoreGrinder puts a grinder that was removed back
+	RestoreGrinder(Grinder)
+	// Available is the count of grinders not in use
+	Available() int
+	// Waiting is the count of orders waiting on a grinder
+	Waiting() int
+	Utilization() []GrinderUsage
+	// Name is what the grinder is called in the reports
+	Name(Grinder) string
+	// Machine is the grinder called the name in the reports,
+	// nil if there isn't one
+	Machine(name string) Grinder
+}
+
+// GrinderUsage is how long a grinder was busy
+type GrinderUsage struct {
+	Grinder string
+	Busy    time.Duration
+	InUse   bool
+}
+
+// grinderLease tracks when a grinder was taken from the
+// pool, a zero time is a grinder in the pool
+type grinderLease struct {
+	name     string
+	leasedAt time.Time
+	busy     time.Duration
 }
 
 type grinderPool struct {
 	sharedPool[Grinder]
+	leaseLock *sync.Mutex
+	leases    map[Grinder]*grinderLease
+	machines  []Grinder
 }
 
 func NewGrinderPool(grinders ...Grinder) GrinderPool {
 	result := &grinderPool{
-		sharedPool[Grinder]{
+		sharedPool: sharedPool[Grinder]{
 			items:  make([]Grinder, 0, len(grinders)),
 			signal: *sync.NewCond(&sync.Mutex{}),
 		},
+		leaseLock: &sync.Mutex{},
+		leases:    map[Grinder]*grinderLease{},
 	}
 
 	for _, g := range grinders {
-		result.AddToPool(g)
+		result.AddGrinder(g)
 	}
 
 	return result
 }
 
+// AddGrinder puts a new grinder in the pool or returns one in use
 func (gp *grinderPool) AddGrinder(g Grinder) {
+	gp.leaseLock.Lock()
+	if lease, found := gp.leases[g]; !found {
+		gp.leases[g] = &grinderLease{name: fmt.Sprintf("Grinder-%d", len(gp.machines))}
+		gp.machines = append(gp.machines, g)
+	} else if !lease.leasedAt.IsZero() {
+		lease.busy += time.Since(lease.leasedAt)
+		lease.leasedAt = time.Time{}
+	}
+	gp.leaseLock.Unlock()
+
 	gp.AddToPool(g)
 }
 
+func (gp *grinderPool) RemoveGrinder(g Grinder) {
+	gp.RemoveFromPool(func(item Grinder) bool {
+		return item == g
+	})
+}
+
+func (gp *grinderPool) RestoreGrinder(g Grinder) {
+	gp.restoreToPool(g)
+}
+
+func (gp *grinderPool) Available() int {
+	return gp.available()
+}
+
+func (gp *grinderPool) Waiting() int {
+	return gp.waiting()
+}
+
+func (gp *grinderPool) Name(g Grinder) string {
+	gp.leaseLock.Lock()
+	defer gp.leaseLock.Unlock()
+
+	if lease, found := gp.leases[g]; found {
+		return lease.name
+	}
+	return "unknown grinder"
+}
+
+func (gp *grinderPool) Machine(name string) Grinder {
+	gp.leaseLock.Lock()
+	defer gp.leaseLock.Unlock()
+
+	for _, g := range gp.machines {
+		if gp.leases[g].name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Utilization reports the busy time of every grinder, including
+// the time so far of grinders in use right now
+func (gp *grinderPool) Utilization() []GrinderUsage {
+	gp.leaseLock.Lock()
+	defer gp.leaseLock.Unlock()
+
+	result := []GrinderUsage{}
+	for _, g := range gp.machines {
+		lease := gp.leases[g]
+		usage := GrinderUsage{Grinder: lease.name, Busy: lease.busy}
+		if !lease.leasedAt.IsZero() {
+			usage.Busy += time.Since(lease.leasedAt)
+			usage.InUse = true
+		}
+		result = append(result, usage)
+	}
+
+	return result
+}
+
 func (gp *grinderPool) GetGrinder() Grinder {
-	return gp.GetFromPool()
+	return gp.lease(gp.GetFromPool())
+}
+
+// GetGrinderFor waits for a grinder that can take the variety,
+// preferring one already loaded with it to skip the purge
+func (gp *grinderPool) GetGrinderFor(variety BeanVariety) Grinder {
+	return gp.lease(gp.GetFromPoolMatching(
+		func(g Grinder) bool {
+			return g.Accepts(variety)
+		},
+		func(g Grinder) bool {
+			loaded, isLoaded := g.Loaded()
+			return isLoaded && loaded == variety
+		}))
+}
+
+// lease marks the grinder as in use
+func (gp *grinderPool) lease(g Grinder) Grinder {
+	gp.leaseLock.Lock()
+	defer gp.leaseLock.Unlock()
+
+	if lease, found := gp.leases[g]; found {
+		lease.leasedAt = time.Now()
+	}
+
+	return g
 }
 
 type BrewerPool interface {
+	// AddBrewer puts a machine in the pool with a slot for each cup
 	AddBrewer(Brewer)
+	// ReturnBrewer gives back a slot leased by one of the Get calls
+	ReturnBrewer(Brewer)
+	// RemoveBrewer takes the machine out, busy slots are
+	// taken out as they're returned
+	RemoveBrewer(Brewer)
+	// RestoreBrewer puts a machine that was removed back
+	// with all its slots
+	RestoreBrewer(Brewer)
 	GetBrewer() Brewer
+	GetBrewerFor(MenuItem) Brewer
+	GetBatchBrewer() Brewer
+	Utilization() []SlotUsage
+	// Available is the count of free slots across the brewers
+	Available() int
+	// Waiting is the count of orders waiting on a brewer
+	Waiting() int
+	// Name is what the brewer is called in the reports
+	Name(Brewer) string
+	// Machine is the brewer called the name in the reports,
+	// nil if there isn't one
+	Machine(name string) Brewer
 }
 
+// SlotUsage is how long one cup slot of a brewer was busy
+type SlotUsage struct {
+	Brewer string
+	Slot   int
+	Busy   time.Duration
+	InUse  bool
+}
+
+// brewerSlots tracks when each slot of a machine was leased,
+// a zero time is a free slot
+type brewerSlots struct {
+	name     string
+	leasedAt []time.Time
+	busy     []time.Duration
+}
+
+// a brewer is in the pool once for every free slot so
+// several orders can brew on one machine at a time
 type brewerPool struct {
 	sharedPool[Brewer]
+	slotLock *sync.Mutex
+	slots    map[Brewer]*brewerSlots
+	machines []Brewer
 }
 
 func NewBrewerPool(brewers ...Brewer) BrewerPool {
 	result := &brewerPool{
-		sharedPool[Brewer]{
+		sharedPool: sharedPool[Brewer]{
 			items:  make([]Brewer, 0, len(brewers)),
 			signal: *sync.NewCond(&sync.Mutex{}),
 		},
+		slotLock: &sync.Mutex{},
+		slots:    map[Brewer]*brewerSlots{},
 	}
 
 	for _, b := range brewers {
-		result.AddToPool(b)
+		result.AddBrewer(b)
 	}
 
 	return result
 }
 
 func (bp *brewerPool) AddBrewer(b Brewer) {
+	cups := b.Cups()
+	if cups < 1 {
+		cups = 1
+	}
+
+	bp.slotLock.Lock()
+	bp.slots[b] = &brewerSlots{
+		name:     fmt.Sprintf("%s-%d", b.Method(), len(bp.machines)),
+		leasedAt: make([]time.Time, cups),
+		busy:     make([]time.Duration, cups),
+	}
+	bp.machines = append(bp.machines, b)
+	bp.slotLock.Unlock()
+
+	for i := 0; i < cups; i++ {
+		bp.AddToPool(b)
+	}
+}
+
+func (bp *brewerPool) ReturnBrewer(b Brewer) {
+	bp.slotLock.Lock()
+	if slots, found := bp.slots[b]; found {
+		// slots are interchangeable, free the first busy one
+		for i, leasedAt := range slots.leasedAt {
+			if !leasedAt.IsZero() {
+				slots.busy[i] += time.Since(leasedAt)
+				slots.leasedAt[i] = time.Time{}
+				break
+			}
+		}
+	}
+	bp.slotLock.Unlock()
+
 	bp.AddToPool(b)
 }
 
+func (bp *brewerPool) RemoveBrewer(b Brewer) {
+	bp.slotLock.Lock()
+	slots, found := bp.slots[b]
+	bp.slotLock.Unlock()
+	if !found {
+		return
+	}
+
+	// the usage so far stays in the report
+	for range slots.leasedAt {
+		bp.RemoveFromPool(func(item Brewer) bool {
+			return item == b
+		})
+	}
+}
+
+func (bp *brewerPool) RestoreBrewer(b Brewer) {
+	bp.slotLock.Lock()
+	slots, found := bp.slots[b]
+	bp.slotLock.Unlock()
+	if !found {
+		return
+	}
+
+	// a removal still waiting on a slot in use is dropped,
+	// the slot comes back when it's returned
+	for range slots.leasedAt {
+		bp.restoreToPool(b)
+	}
+}
+
+func (bp *brewerPool) Available() int {
+	return bp.available()
+}
+
+func (bp *brewerPool) Waiting() int {
+	return bp.waiting()
+}
+
+func (bp *brewerPool) Name(b Brewer) string {
+	bp.slotLock.Lock()
+	defer bp.slotLock.Unlock()
+
+	if slots, found := bp.slots[b]; found {
+		return slots.name
+	}
+	return "unknown brewer"
+}
+
+func (bp *brewerPool) Machine(name string) Brewer {
+	bp.slotLock.Lock()
+	defer bp.slotLock.Unlock()
+
+	for _, b := range bp.machines {
+		if bp.slots[b].name == name {
+			return b
+		}
+	}
+	return nil
+}
+
 func (bp *brewerPool) GetBrewer() Brewer {
-	return bp.GetFromPool()
+	return bp.lease(bp.GetFromPool())
+}
+
+// GetBrewerFor waits for a brewer that can make a cup of the item,
+// batch brewers are kept for filling carafes
+func (bp *brewerPool) GetBrewerFor(item MenuItem) Brewer {
+	return bp.lease(bp.GetFromPoolMatching(
+		func(b Brewer) bool {
+			return b.Method() != BatchDrip && item.BrewableWith(b.Method())
+		},
+		anyItem[Brewer]))
+}
+
+// GetBatchBrewer waits for a brewer that can fill a carafe
+func (bp *brewerPool) GetBatchBrewer() Brewer {
+	return bp.lease(bp.GetFromPoolMatching(
+		func(b Brewer) bool {
+			return b.Method() == BatchDrip
+		},
+		anyItem[Brewer]))
+}
+
+// Utilization reports the busy time of every slot, including
+// the time so far of slots that are leased right now
+func (bp *brewerPool) Utilization() []SlotUsage {
+	bp.slotLock.Lock()
+	defer bp.slotLock.Unlock()
+
+	result := []SlotUsage{}
+	for _, b := range bp.machines {
+		slots := bp.slots[b]
+		for i, busy := range slots.busy {
+			usage := SlotUsage{Brewer: slots.name, Slot: i, Busy: busy}
+			if !slots.leasedAt[i].IsZero() {
+				usage.Busy += time.Since(slots.leasedAt[i])
+				usage.InUse = true
+			}
+			result = append(result, usage)
+		}
+	}
+
+	return result
+}
+
+// lease marks a free slot of the brewer as busy
+func (bp *brewerPool) lease(b Brewer) Brewer {
+	bp.slotLock.Lock()
+	defer bp.slotLock.Unlock()
+
+	if slots, found := bp.slots[b]; found {
+		for i, leasedAt := range slots.leasedAt {
+			if leasedAt.IsZero() {
+				slots.leasedAt[i] = time.Now()
+				break
+			}
+		}
+	}
+
+	return b
 }
 
 type kioskPool struct {
@@ -99,6 +558,13 @@ type kioskPool struct {
 type KioskPool interface {
 	AddKiosk(OrderingKiosk)
 	GetKiosk() OrderingKiosk
+	// RemoveKiosk takes a kiosk out of service, if they're all
+	// in use it's the next one a customer leaves
+	RemoveKiosk()
+	// Available is the count of kiosks no one is at
+	Available() int
+	// Waiting is the count of customers waiting on a kiosk
+	Waiting() int
 }
 
 func NewKioskPool() KioskPool {
@@ -120,7 +586,21 @@ func (kp *kioskPool) AddKiosk(ok OrderingKiosk) {
 
 func (kp *kioskPool) GetKiosk() OrderingKiosk {
 	// the kiosks from the pool are valid
+	waitStart := time.Now()
 	result := kp.GetFromPool()
 	result.setValidity(true)
+	result.setWaitStart(waitStart)
 	return result
 }
+
+func (kp *kioskPool) RemoveKiosk() {
+	kp.RemoveFromPool(anyItem[OrderingKiosk])
+}
+
+func (kp *kioskPool) Available() int {
+	return kp.available()
+}
+
+func (kp *kioskPool) Waiting() int {
+	return kp.waiting()
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPool() *sharedPool[string] {
+	return &sharedPool[string]{signal: *sync.NewCond(&sync.Mutex{})}
+}
+
+// getInTurn starts a wait on the pool and returns once it's in line
+func getInTurn(pool *sharedPool[string], match func(string) bool, prefer func(string) bool) chan string {
+	got := make(chan string, 1)
+	inLine := pool.waiting() + 1
+	go func() {
+		got <- pool.GetFromPoolMatching(match, prefer)
+	}()
+	for pool.waiting() != inLine {
+		time.Sleep(time.Millisecond)
+	}
+
+	return got
+}
+
+func onlyItem(want string) func(string) bool {
+	return func(item string) bool {
+		return item == want
+	}
+}
+
+func TestPoolWaitersFirstComeFirstServed(t *testing.T) {
+	pool := newTestPool()
+	first := getInTurn(pool, anyItem[string], anyItem[string])
+	second := getInTurn(pool, anyItem[string], anyItem[string])
+	third := getInTurn(pool, anyItem[string], anyItem[string])
+
+	// each item goes to the one waiting longest, whoever wakes up first
+	for i, waiter := range []chan string{first, second, third} {
+		item := string(rune('a' + i))
+		pool.AddToPool(item)
+		assert.Equal(t, item, <-waiter)
+		assert.Equal(t, 2-i, pool.waiting())
+	}
+}
+
+func TestPoolWaitersPickInTurn(t *testing.T) {
+	pool := newTestPool()
+
+	// the first one waiting gets the item they prefer even
+	// though the one after them can't take anything else
+	first := getInTurn(pool, anyItem[string], onlyItem("loaded"))
+	second := getInTurn(pool, onlyItem("loaded"), anyItem[string])
+	pool.AddToPool("loaded")
+	assert.Equal(t, "loaded", <-first)
+	assert.Equal(t, 1, pool.waiting())
+
+	// an item the first in line can't take goes to the next
+	third := getInTurn(pool, anyItem[string], anyItem[string])
+	pool.AddToPool("other")
+	assert.Equal(t, "other", <-third)
+	pool.AddToPool("loaded")
+	assert.Equal(t, "loaded", <-second)
+	assert.Equal(t, 0, pool.waiting())
+}
@@ -1,5 +1,7 @@
 package models
 
+import "blreynolds4/coffeeshop/units"
+
 // Test Helpers
 func getTestGrinders() GrinderPool {
 	return NewGrinderPool(&MockGrinder{})
@@ -12,19 +14,40 @@ func getTestBrewers() BrewerPool {
 func getTestMenuItem() MenuItem {
 	return MenuItem{
 		Name:        "Regular Coffee",
-		Size:        8,
-		CoffeeRatio: 2,
+		Size:        240,
+		CoffeeRatio: 16,
+		Variety:     getTestVariety(),
 	}
 }
 
+func getTestVariety() BeanVariety {
+	return BeanVariety{Origin: "Test", Roast: MediumRoast}
+}
+
 type MockGrinder struct{}
 
 func (mg *MockGrinder) Grind(b Beans) Beans {
 	return b
 }
 
+func (mg *MockGrinder) Loaded() (BeanVariety, bool) {
+	return BeanVariety{}, false
+}
+
+func (mg *MockGrinder) Accepts(BeanVariety) bool {
+	return true
+}
+
 type MockBrewer struct{}
 
-func (mb *MockBrewer) Brew(finishedVolume int, beans Beans) *Coffee {
-	return &Coffee{sizeOunces: finishedVolume}
+func (mb *MockBrewer) Brew(finishedVolume units.Milliliters, beans Beans) *Coffee {
+	return NewCoffee(finishedVolume, beans, mb)
+}
+
+func (mb *MockBrewer) Method() BrewMethod {
+	return Drip
+}
+
+func (mb *MockBrewer) Cups() int {
+	return 1
 }
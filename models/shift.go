@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// how often a barista stuck working overtime checks if
+// someone has come in to take over
+const overtimeCheck = 10 * SimSecond
+
+// Break is time off in the middle of a shift, starting
+// the given time after the shop opens
+type Break struct {
+	Start  time.Duration
+	Length time.Duration
+}
+
+// Shift is when a barista works, as times after the shop opens.
+// The zero Shift works from open to close.
+type Shift struct {
+	Start time.Duration
+	// End of 0 works until the shop closes
+	End    time.Duration
+	Breaks []Break
+}
+
+// at is whether the shift is working at the time since the shop
+// opened and when the next change is, 0 if there isn't one
+func (s Shift) at(sinceOpen time.Duration) (bool, time.Duration) {
+	if sinceOpen < s.Start {
+		return false, s.Start
+	}
+	if s.End > 0 && sinceOpen >= s.End {
+		return false, 0
+	}
+
+	next := s.End
+	for _, br := range s.Breaks {
+		breakEnd := br.Start + br.Length
+		if sinceOpen >= br.Start && sinceOpen < breakEnd {
+			return false, breakEnd
+		}
+		if br.Start > sinceOpen && (next == 0 || br.Start < next) {
+			next = br.Start
+		}
+	}
+
+	return true, next
+}
+
+// roster tracks who is clocked in so a barista leaving
+// can hand their orders to someone still working.  The last
+// barista clocked in can't leave while there is work to do.
+type roster struct {
+	lock      *sync.Mutex
+	changed   *sync.Cond
+	openedAt  time.Time
+	baristas  []*barista
+	clockedIn map[*barista]time.Time
+	staffed   time.Duration
+	handoffs  int
+	closed    chan struct{}
+}
+
+func newRoster(openedAt time.Time) *roster {
+	lock := &sync.Mutex{}
+	return &roster{
+		lock:      lock,
+		changed:   sync.NewCond(lock),
+		openedAt:  openedAt,
+		clockedIn: map[*barista]time.Time{},
+		closed:    make(chan struct{}),
+	}
+}
+
+func (r *roster) sinceOpen() time.Duration {
+	return time.Since(r.openedAt)
+}
+
+func (r *roster) join(b *barista) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.baristas = append(r.baristas, b)
+}
+
+// close stops anyone else clocking in, the ones working finish up
+func (r *roster) close() {
+	close(r.closed)
+}
+
+func (r *roster) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *roster) clockIn(b *barista) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	fmt.Println(b.Name, "clocked in")
+	r.clockedIn[b] = time.Now()
+	r.changed.Broadcast()
+}
+
+// clockOut lets the barista leave if someone else is working.  When
+// they're done for the day they can also leave if nobody has orders
+// left to hand off.
+func (r *roster) clockOut(b *barista, doneForDay bool) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// an order may have been handed to them on the way out
+	if doneForDay && b.getCurrentOrderCount() > 0 {
+		return false
+	}
+	if len(r.clockedIn) < 2 && !(doneForDay && r.nothingToHandOff()) {
+		return false
+	}
+
+	fmt.Println(b.Name, "clocked out")
+	r.staffed += time.Since(r.clockedIn[b])
+	delete(r.clockedIn, b)
+	return true
+}
+
+func (r *roster) nothingToHandOff() bool {
+	for _, b := range r.baristas {
+		if _, working := r.clockedIn[b]; !working && b.getCurrentOrderCount() > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handOff gives an order step to the least busy barista working,
+// waiting for someone to clock in if nobody is.  A tie goes to
+// whoever started first.
+func (r *roster) handOff(from *barista, event OrderEvent) {
+	r.lock.Lock()
+	var to *barista
+	for to == nil {
+		for _, b := range r.baristas {
+			if _, working := r.clockedIn[b]; !working {
+				continue
+			}
+			if to == nil || b.getCurrentOrderCount() < to.getCurrentOrderCount() {
+				to = b
+			}
+		}
+		if to == nil {
+			r.changed.Wait()
+		}
+	}
+
+	// move the order over before letting go of the lock so it's
+	// always counted by someone
+	to.incrementOrderCount()
+	from.decrementOrderCount()
+	r.handoffs += 1
+	r.lock.Unlock()
+	event.GetOrder().workedBy(to.Name)
+
+	fmt.Println(from.Name, "handed off the order for", event.GetOrder().Customer, "to", to.Name)
+	to.activeOrders <- event
+}
+
+// staffedTime is the time baristas have been clocked in so far
+func (r *roster) staffedTime() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := r.staffed
+	for _, since := range r.clockedIn {
+		result += time.Since(since)
+	}
+
+	return result
+}
+
+// orderCount is the orders every barista has going, working or not
+func (r *roster) orderCount() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := 0
+	for _, b := range r.baristas {
+		result += b.getCurrentOrderCount()
+	}
+
+	return result
+}
+
+func (r *roster) handoffCount() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.handoffs
+}
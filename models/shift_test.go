@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftAt(t *testing.T) {
+	shift := Shift{
+		Start:  10 * time.Second,
+		End:    60 * time.Second,
+		Breaks: []Break{{Start: 30 * time.Second, Length: 5 * time.Second}},
+	}
+
+	tests := []struct {
+		sinceOpen time.Duration
+		working   bool
+		next      time.Duration
+	}{
+		{0, false, 10 * time.Second},
+		{10 * time.Second, true, 30 * time.Second},
+		{32 * time.Second, false, 35 * time.Second},
+		{35 * time.Second, true, 60 * time.Second},
+		{60 * time.Second, false, 0},
+	}
+	for _, test := range tests {
+		working, next := shift.at(test.sinceOpen)
+		assert.Equal(t, test.working, working, test.sinceOpen)
+		assert.Equal(t, test.next, next, test.sinceOpen)
+	}
+
+	// the zero shift works all day
+	working, next := Shift{}.at(time.Hour)
+	assert.True(t, working)
+	assert.Equal(t, time.Duration(0), next)
+}
+
+func TestShiftHandOff(t *testing.T) {
+	// the first barista leaves while the order is brewing
+	// and the second one finishes it
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		2, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		NewBrewerPool(NewBrewer(1)),
+		WithShifts(
+			Shift{End: 60 * SimSecond},
+			Shift{Start: 30 * SimSecond}))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+
+	c := order.Wait()
+	assert.Equal(t, getTestMenuItem().Size, c.Volume())
+	shop.Close()
+
+	stats := shop.Stats()
+	assert.Equal(t, 1, stats.OrdersServed)
+	assert.Equal(t, 1, stats.Handoffs)
+	assert.Greater(t, stats.StaffedTime, time.Duration(0))
+}
+
+func TestShiftOvertime(t *testing.T) {
+	// nobody comes in to take over, the barista stays to finish
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		NewBrewerPool(NewBrewer(1)),
+		WithShifts(Shift{End: 10 * SimSecond}))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	shop.Close()
+
+	assert.NotNil(t, order.Wait())
+	stats := shop.Stats()
+	assert.Equal(t, 0, stats.Handoffs)
+	// the brew alone takes 240 seconds
+	assert.Greater(t, stats.StaffedTime, 240*SimSecond)
+}
+
+func TestHandOffTieGoesToFirstOnRoster(t *testing.T) {
+	roster := newRoster(time.Now())
+	leaving := newBarista("Leaving", 3, nil, nil, nil)
+	first := newBarista("First", 3, nil, nil, nil)
+	second := newBarista("Second", 3, nil, nil, nil)
+	for _, b := range []*barista{leaving, first, second} {
+		b.roster = roster
+		roster.join(b)
+	}
+	roster.clockIn(second)
+	roster.clockIn(first)
+
+	// the least busy gets each step, a tie goes to whoever is
+	// first on the roster and not to whoever the map gives up
+	for _, to := range []*barista{first, second, first} {
+		leaving.incrementOrderCount()
+		order := NewOrder("test", getTestMenuItem())
+		roster.handOff(leaving, NewGrindCompleteEvent(order, Beans{}))
+		assert.Equal(t, order, (<-to.activeOrders).GetOrder())
+	}
+	assert.Equal(t, 2, first.getCurrentOrderCount())
+	assert.Equal(t, 1, second.getCurrentOrderCount())
+	assert.Equal(t, 0, leaving.getCurrentOrderCount())
+	assert.Equal(t, 3, roster.handoffs)
+}
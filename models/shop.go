@@ -2,7 +2,10 @@ package models
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"sync"
+	"time"
 )
 
 type OrderChannel chan *Order
@@ -10,20 +13,25 @@ type OrderChannel chan *Order
 type OrderingKiosk interface {
 	CreateOrder(name string, item MenuItem) *Order
 	setValidity(valid bool)
+	// setWaitStart is when the customer started waiting for the kiosk
+	setWaitStart(time.Time)
 }
 
 type orderingKiosk struct {
 	// the kiosk is only usable for an order if it's valid
 	// validity is true to start and when gotten from a pool
 	// invalid when put back into the pool
-	valid  bool
-	orders OrderChannel
+	valid     bool
+	orders    OrderChannel
+	book      *orderBook
+	waitStart time.Time
 }
 
-func newOrderingKiosk(oc OrderChannel) OrderingKiosk {
+func newOrderingKiosk(oc OrderChannel, book *orderBook) OrderingKiosk {
 	return &orderingKiosk{
 		valid:  true,
 		orders: oc,
+		book:   book,
 	}
 }
 
@@ -31,24 +39,242 @@ func (ok *orderingKiosk) setValidity(valid bool) {
 	ok.valid = valid
 }
 
+func (ok *orderingKiosk) setWaitStart(waitStart time.Time) {
+	ok.waitStart = waitStart
+}
+
 func (ok *orderingKiosk) CreateOrder(name string, item MenuItem) *Order {
-	if !ok.valid {
+	if !ok.valid || ok.book.turnAway(item) {
 		return nil
 	}
 
-	// create the order and put it in the shop order channel
+	// create the order and put it in the shop order channel,
+	// there's no order if the shop closed while at the kiosk
 	o := NewOrder(name, item)
-
+	if !ok.book.place(o, ok.orders, ok.waitStart) {
+		return nil
+	}
 	fmt.Println(name, "ordered", item.Name)
-	ok.orders <- o
 
 	return o
 }
 
+// keptFinished is how many finished orders can still be looked up
+const keptFinished = 1000
+
+// orderBook keeps the orders placed at the kiosks so they can be
+// looked up by ID, and stops taking them once the shop closes.  An
+// order that's finished moves out of the open orders, only the last
+// ones finished are kept so a long day doesn't hold every order.
+type orderBook struct {
+	// placing an order holds a read lock so closing waits
+	// for the orders already at a kiosk
+	closeLock *sync.RWMutex
+	closed    bool
+	lock      *sync.Mutex
+	placed    map[int64]*Order
+	finished  map[int64]*Order
+	// the finished orders oldest first, the first go when it's full
+	finishedIDs []int64
+	events      *eventBus
+	metrics     *shopMetrics
+	tracer      Tracer
+	journal     *Journal
+	stats       *statsRecorder
+	// the items on the menu that can't be ordered right now
+	soldOut map[string]bool
+}
+
+func newOrderBook(events *eventBus, metrics *shopMetrics, tracer Tracer, journal *Journal) *orderBook {
+	return &orderBook{
+		closeLock: &sync.RWMutex{},
+		lock:      &sync.Mutex{},
+		placed:    map[int64]*Order{},
+		finished:  map[int64]*Order{},
+		soldOut:   map[string]bool{},
+		events:    events,
+		metrics:   metrics,
+		tracer:    tracer,
+		journal:   journal,
+	}
+}
+
+// place sends the order to the baristas, false if the shop is closed.
+// Kiosks outside a shop have a nil book and just send it.
+func (ob *orderBook) place(o *Order, orders OrderChannel, waitStart time.Time) bool {
+	if ob == nil {
+		orders <- o
+		return true
+	}
+
+	ob.closeLock.RLock()
+	defer ob.closeLock.RUnlock()
+
+	if ob.closed {
+		return false
+	}
+
+	o.events = ob.events
+	o.metrics = ob.metrics
+	o.journal = ob.journal
+	o.book = ob
+	ob.journal.placed(o)
+	ob.metrics.placed(o.Status)
+	if waitStart.IsZero() {
+		waitStart = o.orderedAt
+	}
+	o.trace = newOrderTrace(ob.tracer, o, waitStart)
+	ob.lock.Lock()
+	ob.placed[o.ID] = o
+	ob.lock.Unlock()
+	ob.events.publish(ShopEvent{Kind: StatusEvent, OrderID: o.ID, Customer: o.Customer, Status: o.Status})
+
+	orders <- o
+	return true
+}
+
+func (ob *orderBook) setSoldOut(item string, soldOut bool) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	ob.soldOut[item] = soldOut
+}
+
+// turnAway is true when the item is sold out, the customer
+// leaves without ordering.  Kiosks outside a shop have a nil
+// book and sell everything.
+func (ob *orderBook) turnAway(item MenuItem) bool {
+	if ob == nil {
+		return false
+	}
+
+	ob.lock.Lock()
+	soldOut := ob.soldOut[item.Name]
+	ob.lock.Unlock()
+	if soldOut && ob.stats != nil {
+		ob.stats.turnedAway()
+	}
+
+	return soldOut
+}
+
+// finish moves the order out of the open orders, the order's
+// lock is held so the book's lock is always taken after it.
+// Orders outside a shop have a nil book.
+func (ob *orderBook) finish(o *Order) {
+	if ob == nil {
+		return
+	}
+
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if _, open := ob.placed[o.ID]; !open {
+		return
+	}
+	delete(ob.placed, o.ID)
+	ob.finished[o.ID] = o
+	ob.finishedIDs = append(ob.finishedIDs, o.ID)
+	if len(ob.finishedIDs) > keptFinished {
+		delete(ob.finished, ob.finishedIDs[0])
+		ob.finishedIDs = ob.finishedIDs[1:]
+	}
+}
+
+// openOrders are the orders that aren't complete or cancelled
+// in the order they were placed
+func (ob *orderBook) openOrders() []*Order {
+	ob.lock.Lock()
+	result := make([]*Order, 0, len(ob.placed))
+	for _, order := range ob.placed {
+		result = append(result, order)
+	}
+	ob.lock.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// order is an open order or one of the last ones finished
+func (ob *orderBook) order(id int64) *Order {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if order, found := ob.placed[id]; found {
+		return order
+	}
+	return ob.finished[id]
+}
+
+// close takes no more orders once the ones at a kiosk are placed
+func (ob *orderBook) close() {
+	ob.closeLock.Lock()
+	defer ob.closeLock.Unlock()
+
+	ob.closed = true
+}
+
+func (ob *orderBook) isClosed() bool {
+	ob.closeLock.RLock()
+	defer ob.closeLock.RUnlock()
+
+	return ob.closed
+}
+
 type CoffeeShop interface {
 	WaitForOrderingKiosk() OrderingKiosk
 	LeaveOrderingKiosk(OrderingKiosk)
 	Close()
+	Stats() Stats
+
+	// AddBarista starts a barista working, the name is made up if the
+	// profile doesn't have one.  It's the name of the new barista or
+	// empty if the shop is closed.
+	AddBarista(profile BaristaProfile) string
+	// RemoveBarista has the barista finish their orders and go home.
+	// It's false if there's no barista by the name or they're the last
+	// one, nobody would be left to make the orders.
+	RemoveBarista(name string) bool
+	Baristas() []string
+	// TakeBreak sends the barista on a break starting now, someone
+	// else working takes their orders.  It's false if there's no
+	// barista by the name.
+	TakeBreak(name string, length time.Duration) bool
+	// OpenKiosk adds a kiosk, it's false if the shop is closed
+	OpenKiosk() bool
+	CloseKiosk()
+
+	// BreakMachine takes a grinder or brewer out of service by its name
+	// in the reports, one in use is taken out once it's done.  It's false
+	// if there's no machine by the name or it's already broken.
+	BreakMachine(name string) bool
+	// RepairMachine puts a broken machine back in service
+	RepairMachine(name string) bool
+
+	// SetSoldOut stops or starts taking orders for an item on the
+	// menu, it's false if the item isn't on the menu
+	SetSoldOut(item string, soldOut bool) bool
+
+	// Order is an order placed at a kiosk by its ID, nil if there's no
+	// such order.  Only the last 1000 orders finished are kept.
+	Order(id int64) *Order
+
+	// Requeue puts an order recovered from a journal back in line
+	// for the baristas, it's false if the shop is closed
+	Requeue(order *Order) bool
+
+	// Snapshot is what's going on in the shop right now
+	Snapshot() Snapshot
+
+	// WriteMetrics writes the shop's metrics in the Prometheus text format
+	WriteMetrics(w io.Writer) error
+
+	// Subscribe to the events of an order, or everything in the shop
+	// for 0.  Call the returned func to stop.
+	Subscribe(orderID int64) (<-chan ShopEvent, func())
 }
 
 type coffeeShop struct {
@@ -60,39 +286,277 @@ type coffeeShop struct {
 	orders    OrderChannel
 	closed    bool
 	closeWait *sync.WaitGroup
+	carafes   *carafeStation
+	stats     *statsRecorder
+	openedAt  time.Time
+	closedAt  time.Time
+	profiles  []BaristaProfile
+	seed      int64
+	shifts    []Shift
+	roster    *roster
+	// staffLock guards the baristas, closing and when the shop
+	// closed while baristas are added and removed
+	staffLock        *sync.Mutex
+	hired            int
+	maxBaristaOrders int
+	autoscale        *AutoscalePolicy
+	events           *eventBus
+	book             *orderBook
+	metrics          *shopMetrics
+	tracer           Tracer
+	journal          *Journal
+	// machineLock guards the machines out of service
+	machineLock *sync.Mutex
+	broken      map[string]bool
 }
 
-func NewCoffeeShop(menu Menu, kioskCount int, baristaCount int, maxBaristaOrders int, grinders GrinderPool, brewers BrewerPool) CoffeeShop {
+type ShopOption func(*coffeeShop)
+
+// WithBatchBrewing serves items that can be batch brewed from carafes.
+// A carafe holds up to maxCups and is dumped after the hold time.
+// The brewer pool needs a batch brewer to fill them.
+func WithBatchBrewing(holdTime time.Duration, maxCups int) ShopOption {
+	return func(cs *coffeeShop) {
+		if maxCups < 1 {
+			maxCups = 1
+		}
+		cs.carafes = newCarafeStation(holdTime, maxCups, cs.stats)
+	}
+}
+
+// WithBaristaProfiles sets how skilled each barista is, in order.
+// Baristas past the end of the profiles get the default profile.
+func WithBaristaProfiles(profiles ...BaristaProfile) ShopOption {
+	return func(cs *coffeeShop) {
+		cs.profiles = profiles
+	}
+}
+
+// WithSeed makes the baristas' mistakes repeatable, 0 seeds from the clock
+func WithSeed(seed int64) ShopOption {
+	return func(cs *coffeeShop) {
+		cs.seed = seed
+	}
+}
+
+// WithShifts sets when each barista works, in order.  Baristas
+// past the end of the shifts work from open to close.
+func WithShifts(shifts ...Shift) ShopOption {
+	return func(cs *coffeeShop) {
+		cs.shifts = shifts
+	}
+}
+
+func NewCoffeeShop(menu Menu, kioskCount int, baristaCount int, maxBaristaOrders int, grinders GrinderPool, brewers BrewerPool, opts ...ShopOption) CoffeeShop {
 	result := &coffeeShop{
-		Menu:      menu,
-		baristas:  make([]*barista, 0, baristaCount),
-		grinders:  grinders,
-		brewers:   brewers,
-		kiosks:    NewKioskPool(),
-		orders:    make(OrderChannel, 10*baristaCount),
-		closeWait: &sync.WaitGroup{},
+		Menu:             menu,
+		baristas:         make([]*barista, 0, baristaCount),
+		grinders:         grinders,
+		brewers:          brewers,
+		kiosks:           NewKioskPool(),
+		orders:           make(OrderChannel, 10*baristaCount),
+		closeWait:        &sync.WaitGroup{},
+		stats:            newStatsRecorder(),
+		openedAt:         time.Now(),
+		staffLock:        &sync.Mutex{},
+		maxBaristaOrders: maxBaristaOrders,
+		events:           newEventBus(),
+		metrics:          newShopMetrics(),
+		machineLock:      &sync.Mutex{},
+		broken:           map[string]bool{},
 	}
+	result.roster = newRoster(result.openedAt)
+
+	for _, opt := range opts {
+		opt(result)
+	}
+	result.book = newOrderBook(result.events, result.metrics, result.tracer, result.journal)
+	result.book.stats = result.stats
 
 	for i := 0; i < kioskCount; i++ {
-		result.kiosks.AddKiosk(newOrderingKiosk(result.orders))
+		result.kiosks.AddKiosk(newOrderingKiosk(result.orders, result.book))
 	}
 
 	for i := 0; i < baristaCount; i++ {
-		name := fmt.Sprintf("Barista-%d", i)
-		b := newBarista(name, maxBaristaOrders, result.orders, result.grinders, result.brewers)
-		result.baristas = append(result.baristas, b)
-		result.closeWait.Add(1)
-		go func(b *barista) {
-			b.ServeCustomers()
-			result.closeWait.Done()
-		}(b)
+		profile := DefaultBaristaProfile("")
+		if i < len(result.profiles) {
+			profile = result.profiles[i]
+		}
+		shift := Shift{}
+		if i < len(result.shifts) {
+			shift = result.shifts[i]
+		}
+		result.hire(profile, shift)
+	}
+
+	if result.autoscale != nil {
+		go result.autoscaler(*result.autoscale)
 	}
 
 	return result
 }
 
+// hire starts a barista working, the caller holds the staff lock
+// or is still making the shop
+func (cs *coffeeShop) hire(profile BaristaProfile, shift Shift) *barista {
+	name := fmt.Sprintf("Barista-%d", cs.hired)
+	if profile.Name == "" {
+		profile.Name = name
+	}
+
+	b := newBarista(profile.Name, cs.maxBaristaOrders, cs.orders, cs.grinders, cs.brewers)
+	b.profile = profile
+	if cs.seed != 0 {
+		b.rand = newLockedRand(cs.seed + int64(cs.hired))
+	}
+	b.shift = shift
+	b.roster = cs.roster
+	b.carafes = cs.carafes
+	b.stats = cs.stats
+	b.events = cs.events
+	cs.hired += 1
+	cs.baristas = append(cs.baristas, b)
+	cs.closeWait.Add(1)
+	go func() {
+		b.ServeCustomers()
+		cs.closeWait.Done()
+	}()
+
+	return b
+}
+
+func (cs *coffeeShop) AddBarista(profile BaristaProfile) string {
+	cs.staffLock.Lock()
+	defer cs.staffLock.Unlock()
+
+	if cs.closed {
+		return ""
+	}
+
+	b := cs.hire(profile, Shift{})
+	fmt.Println(b.Name, "was added to the shop")
+	return b.Name
+}
+
+func (cs *coffeeShop) RemoveBarista(name string) bool {
+	cs.staffLock.Lock()
+	defer cs.staffLock.Unlock()
+
+	if len(cs.baristas) < 2 {
+		return false
+	}
+	for i, b := range cs.baristas {
+		if b.Name == name {
+			cs.baristas = append(cs.baristas[:i], cs.baristas[i+1:]...)
+			b.leave()
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs *coffeeShop) Baristas() []string {
+	cs.staffLock.Lock()
+	defer cs.staffLock.Unlock()
+
+	result := make([]string, 0, len(cs.baristas))
+	for _, b := range cs.baristas {
+		result = append(result, b.Name)
+	}
+
+	return result
+}
+
+func (cs *coffeeShop) TakeBreak(name string, length time.Duration) bool {
+	cs.staffLock.Lock()
+	defer cs.staffLock.Unlock()
+
+	for _, b := range cs.baristas {
+		if b.Name == name {
+			return b.takeBreak(Break{Start: cs.roster.sinceOpen(), Length: length})
+		}
+	}
+
+	return false
+}
+
+func (cs *coffeeShop) BreakMachine(name string) bool {
+	cs.machineLock.Lock()
+	defer cs.machineLock.Unlock()
+
+	if cs.broken[name] {
+		return false
+	}
+	if grinder := cs.grinders.Machine(name); grinder != nil {
+		cs.grinders.RemoveGrinder(grinder)
+	} else if brewer := cs.brewers.Machine(name); brewer != nil {
+		cs.brewers.RemoveBrewer(brewer)
+	} else {
+		return false
+	}
+
+	fmt.Println(name, "broke down")
+	cs.broken[name] = true
+	return true
+}
+
+func (cs *coffeeShop) RepairMachine(name string) bool {
+	cs.machineLock.Lock()
+	defer cs.machineLock.Unlock()
+
+	if !cs.broken[name] {
+		return false
+	}
+	if grinder := cs.grinders.Machine(name); grinder != nil {
+		cs.grinders.RestoreGrinder(grinder)
+	} else {
+		cs.brewers.RestoreBrewer(cs.brewers.Machine(name))
+	}
+
+	fmt.Println(name, "is fixed")
+	delete(cs.broken, name)
+	return true
+}
+
+func (cs *coffeeShop) SetSoldOut(item string, soldOut bool) bool {
+	for _, menuItem := range cs.Menu {
+		if menuItem.Name == item {
+			cs.book.setSoldOut(item, soldOut)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs *coffeeShop) OpenKiosk() bool {
+	if cs.book.isClosed() {
+		return false
+	}
+
+	cs.kiosks.AddKiosk(newOrderingKiosk(cs.orders, cs.book))
+	return true
+}
+
+func (cs *coffeeShop) CloseKiosk() {
+	cs.kiosks.RemoveKiosk()
+}
+
+func (cs *coffeeShop) Requeue(order *Order) bool {
+	return cs.book.place(order, cs.orders, order.orderedAt)
+}
+
+func (cs *coffeeShop) Order(id int64) *Order {
+	return cs.book.order(id)
+}
+
+func (cs *coffeeShop) Subscribe(orderID int64) (<-chan ShopEvent, func()) {
+	return cs.events.subscribe(orderID)
+}
+
 func (cs *coffeeShop) WaitForOrderingKiosk() OrderingKiosk {
-	if !cs.closed {
+	if !cs.book.isClosed() {
 		return cs.kiosks.GetKiosk()
 	}
 
@@ -106,12 +570,38 @@ func (cs *coffeeShop) LeaveOrderingKiosk(k OrderingKiosk) {
 func (cs *coffeeShop) Close() {
 	// take no more orders
 	// anything in progress will finish
+	cs.staffLock.Lock()
 	cs.closed = true
+	cs.book.close()
+	cs.roster.close()
 	close(cs.orders)
+	cs.staffLock.Unlock()
 	fmt.Println("Ordering closed")
 
 	// wait for baristas to finish all orders
 	fmt.Println("Shop closing, finish up")
 	cs.closeWait.Wait()
 	fmt.Println("All baristas done")
+
+	cs.carafes.close()
+	cs.staffLock.Lock()
+	cs.closedAt = time.Now()
+	cs.staffLock.Unlock()
+}
+
+func (cs *coffeeShop) Stats() Stats {
+	result := cs.stats.snapshot()
+	result.BrewerSlots = cs.brewers.Utilization()
+	result.StaffedTime = cs.roster.staffedTime()
+	result.Handoffs = cs.roster.handoffCount()
+	cs.staffLock.Lock()
+	closedAt := cs.closedAt
+	cs.staffLock.Unlock()
+	if closedAt.IsZero() {
+		result.OpenTime = time.Since(cs.openedAt)
+	} else {
+		result.OpenTime = closedAt.Sub(cs.openedAt)
+	}
+
+	return result
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -33,8 +34,306 @@ func TestOrderingAndKioskValidity(t *testing.T) {
 	// wait for the order to be complete and make sure it's right
 	c := order.Wait()
 	assert.NotNil(t, c)
-	assert.Equal(t, getTestMenuItem().Size, c.sizeOunces)
+	assert.Equal(t, getTestMenuItem().Size, c.volume)
 
 	// verify can't get ordering kiosk after close
 	assert.Nil(t, shop.WaitForOrderingKiosk())
 }
+
+func TestLookupOrder(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	assert.Equal(t, order, shop.Order(order.ID))
+	assert.Nil(t, shop.Order(order.ID+1000))
+
+	// a kiosk someone was at when the shop closed takes no order
+	shop.Close()
+	assert.Nil(t, kiosk.CreateOrder("late", getTestMenuItem()))
+	shop.LeaveOrderingKiosk(kiosk)
+}
+
+func TestFinishedOrdersLeaveTheBook(t *testing.T) {
+	book := newOrderBook(nil, nil, nil, nil)
+	orders := make(OrderChannel, keptFinished+1)
+	placed := []*Order{}
+	for i := 0; i <= keptFinished; i++ {
+		order := NewOrder("test", getTestMenuItem())
+		assert.True(t, book.place(order, orders, time.Time{}))
+		placed = append(placed, order)
+	}
+	assert.Len(t, book.openOrders(), keptFinished+1)
+
+	// a finished order can still be looked up but isn't open
+	assert.True(t, placed[0].NotifyCustomer(&Coffee{}))
+	assert.Len(t, book.openOrders(), keptFinished)
+	assert.Equal(t, placed[0], book.order(placed[0].ID))
+
+	// only the last ones finished are kept
+	for _, order := range placed[1:] {
+		assert.True(t, order.Cancel())
+	}
+	assert.Empty(t, book.openOrders())
+	assert.Nil(t, book.order(placed[0].ID))
+	assert.Equal(t, placed[1], book.order(placed[1].ID))
+}
+
+func TestBatchBrewing(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		2, // max orders per barista
+		getTestGrinders(),
+		NewBrewerPool(NewBatchBrewer(1000)),
+		WithBatchBrewing(time.Minute, 4))
+
+	orders := []*Order{}
+	for _, name := range []string{"first", "second", "third"} {
+		kiosk := shop.WaitForOrderingKiosk()
+		orders = append(orders, kiosk.CreateOrder(name, getTestMenuItem()))
+		shop.LeaveOrderingKiosk(kiosk)
+	}
+
+	for _, order := range orders {
+		c := order.Wait()
+		assert.Equal(t, getTestMenuItem().Size, c.volume)
+		assert.Equal(t, BatchDrip, c.method)
+	}
+
+	shop.Close()
+
+	stats := shop.Stats()
+	assert.Equal(t, 3, stats.OrdersServed)
+	assert.Equal(t, 3, stats.CarafeCupsPoured)
+	assert.GreaterOrEqual(t, stats.BatchesBrewed, 1)
+}
+
+func TestBaristaProfiles(t *testing.T) {
+	// a barista that gets every step wrong once in a while
+	clumsy := BaristaProfile{Name: "Clumsy", Speed: 4, ErrorRate: 0.5}
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers(),
+		WithBaristaProfiles(clumsy),
+		WithSeed(1))
+
+	orders := []*Order{}
+	for i := 0; i < 5; i++ {
+		kiosk := shop.WaitForOrderingKiosk()
+		orders = append(orders, kiosk.CreateOrder("test", getTestMenuItem()))
+		shop.LeaveOrderingKiosk(kiosk)
+	}
+	shop.Close()
+
+	// every drink that goes out is the right one
+	for _, order := range orders {
+		c := order.Wait()
+		assert.True(t, passesQA(order, c))
+	}
+
+	stats := shop.Stats()
+	assert.Equal(t, 5, stats.OrdersServed)
+	assert.Greater(t, stats.Remakes, 0)
+	assert.Greater(t, float64(stats.RemakeWastedCoffee), 0.0)
+}
+
+func TestAddAndRemoveBarista(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	assert.Equal(t, "Extra", shop.AddBarista(DefaultBaristaProfile("Extra")))
+	assert.Equal(t, "Barista-2", shop.AddBarista(BaristaProfile{Speed: 2}))
+	assert.Equal(t, []string{"Barista-0", "Extra", "Barista-2"}, shop.Baristas())
+
+	assert.True(t, shop.RemoveBarista("Barista-0"))
+	assert.False(t, shop.RemoveBarista("nobody"))
+	assert.True(t, shop.RemoveBarista("Barista-2"))
+	assert.Equal(t, []string{"Extra"}, shop.Baristas())
+
+	// somebody has to stay to make the orders
+	assert.False(t, shop.RemoveBarista("Extra"))
+	assert.Equal(t, []string{"Extra"}, shop.Baristas())
+
+	// the one left still serves
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	assert.NotNil(t, order.Wait())
+
+	shop.Close()
+	assert.Equal(t, "", shop.AddBarista(DefaultBaristaProfile("Late")))
+}
+
+func TestOpenAndCloseKiosk(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	shop.CloseKiosk()
+	ordered := make(chan *Order)
+	go func() {
+		kiosk := shop.WaitForOrderingKiosk()
+		ordered <- kiosk.CreateOrder("test", getTestMenuItem())
+		shop.LeaveOrderingKiosk(kiosk)
+	}()
+
+	// nobody can order till a kiosk opens
+	select {
+	case <-ordered:
+		assert.Fail(t, "ordered with the kiosk closed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.True(t, shop.OpenKiosk())
+	assert.NotNil(t, (<-ordered).Wait())
+	shop.Close()
+	assert.False(t, shop.OpenKiosk())
+}
+
+func TestBreakAndRepairMachine(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	assert.True(t, shop.BreakMachine("Grinder-0"))
+	assert.False(t, shop.BreakMachine("Grinder-0"))
+	assert.False(t, shop.BreakMachine("Grinder-9"))
+	assert.False(t, shop.RepairMachine("Drip-0"))
+	assert.True(t, shop.BreakMachine("Drip-0"))
+
+	ordered := make(chan *Order)
+	go func() {
+		kiosk := shop.WaitForOrderingKiosk()
+		order := kiosk.CreateOrder("test", getTestMenuItem())
+		shop.LeaveOrderingKiosk(kiosk)
+		order.Wait()
+		ordered <- order
+	}()
+
+	// nothing's made with the machines broken
+	select {
+	case <-ordered:
+		assert.Fail(t, "made a coffee with the machines broken")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.True(t, shop.RepairMachine("Grinder-0"))
+	assert.True(t, shop.RepairMachine("Drip-0"))
+	assert.Equal(t, Complete, (<-ordered).GetStatus())
+	shop.Close()
+}
+
+func TestSoldOut(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	assert.False(t, shop.SetSoldOut("Tea", true))
+	assert.True(t, shop.SetSoldOut("Regular Coffee", true))
+	kiosk := shop.WaitForOrderingKiosk()
+	assert.Nil(t, kiosk.CreateOrder("test", getTestMenuItem()))
+	shop.LeaveOrderingKiosk(kiosk)
+
+	assert.True(t, shop.SetSoldOut("Regular Coffee", false))
+	kiosk = shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	assert.NotNil(t, order.Wait())
+	shop.Close()
+
+	stats := shop.Stats()
+	assert.Equal(t, 1, stats.TurnedAway)
+	assert.Equal(t, 1, stats.OrdersServed)
+}
+
+func TestTakeBreak(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		2, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	assert.False(t, shop.TakeBreak("nobody", time.Second))
+	assert.True(t, shop.TakeBreak("Barista-0", 50*time.Millisecond))
+
+	// the other barista covers the break
+	roster := shop.(*coffeeShop).roster
+	clockedIn := func() int {
+		roster.lock.Lock()
+		defer roster.lock.Unlock()
+		return len(roster.clockedIn)
+	}
+	for clockedIn() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	assert.NotNil(t, order.Wait())
+	_, barista := order.snapshot()
+	assert.Equal(t, "Barista-1", barista)
+
+	// and they're back after it
+	for clockedIn() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	shop.Close()
+}
+
+func TestAutoscaler(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		4, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		NewBrewerPool(NewBrewer(10)),
+		WithAutoscaler(AutoscalePolicy{
+			CheckEvery:  5 * SimSecond,
+			MinBaristas: 1,
+			MaxBaristas: 3,
+			MaxQueue:    1,
+		}))
+
+	orders := []*Order{}
+	for i := 0; i < 8; i++ {
+		kiosk := shop.WaitForOrderingKiosk()
+		orders = append(orders, kiosk.CreateOrder("test", getTestMenuItem()))
+		shop.LeaveOrderingKiosk(kiosk)
+	}
+	for _, order := range orders {
+		order.Wait()
+	}
+
+	// give it a quiet spell to send the extra baristas home
+	time.Sleep(50 * SimSecond)
+	shop.Close()
+
+	stats := shop.Stats()
+	assert.Greater(t, stats.BaristasAdded, 0)
+	assert.Equal(t, stats.BaristasAdded, stats.BaristasRemoved)
+	assert.Len(t, shop.Baristas(), 1)
+	assert.Contains(t, stats.String(), "Autoscaler added")
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Snapshot is what's going on in the shop right now, for screens
+// showing the shop as it runs
+type Snapshot struct {
+	At time.Time
+	// customers waiting on a kiosk and the kiosks no one is at
+	KioskQueue int
+	KiosksFree int
+	// orders placed that no barista has started
+	Queued   []OrderSnapshot
+	Baristas []BaristaSnapshot
+	Grinders []GrinderUsage
+	Brewers  []SlotUsage
+	// orders waiting on a grinder or brewer
+	GrindersWaiting int
+	BrewersWaiting  int
+	Stats           Stats
+	// Closed is true once the shop stops taking orders
+	Closed bool
+}
+
+// BaristaSnapshot is a barista and the orders they're working on
+type BaristaSnapshot struct {
+	Name   string
+	Orders []OrderSnapshot
+}
+
+type OrderSnapshot struct {
+	ID       int64
+	Customer string
+	Item     string
+	Status   OrderStatus
+}
+
+func (cs *coffeeShop) Snapshot() Snapshot {
+	result := Snapshot{
+		At:              time.Now(),
+		KioskQueue:      cs.kiosks.Waiting(),
+		KiosksFree:      cs.kiosks.Available(),
+		Grinders:        cs.grinders.Utilization(),
+		Brewers:         cs.brewers.Utilization(),
+		GrindersWaiting: cs.grinders.Waiting(),
+		BrewersWaiting:  cs.brewers.Waiting(),
+		Stats:           cs.Stats(),
+		Closed:          cs.book.isClosed(),
+	}
+
+	// the baristas on staff in the order they were hired, and
+	// anyone sent home that's still finishing up
+	baristas := map[string]int{}
+	for _, name := range cs.Baristas() {
+		baristas[name] = len(result.Baristas)
+		result.Baristas = append(result.Baristas, BaristaSnapshot{Name: name})
+	}
+	for _, order := range cs.book.openOrders() {
+		snapshot, barista := order.snapshot()
+		if snapshot.Status == Complete || snapshot.Status == Cancelled {
+			// it finished since the book was read
+			continue
+		}
+		if barista == "" {
+			result.Queued = append(result.Queued, snapshot)
+			continue
+		}
+		i, found := baristas[barista]
+		if !found {
+			i = len(result.Baristas)
+			baristas[barista] = i
+			result.Baristas = append(result.Baristas, BaristaSnapshot{Name: barista})
+		}
+		result.Baristas[i].Orders = append(result.Baristas[i].Orders, snapshot)
+	}
+
+	return result
+}
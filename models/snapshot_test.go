@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	// slow enough to see it brewing
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		NewBrewerPool(NewBrewer(2)))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	snapshot := shop.Snapshot()
+	assert.Equal(t, 0, snapshot.KiosksFree)
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+
+	assert.Eventually(t, func() bool {
+		snapshot = shop.Snapshot()
+		return len(snapshot.Baristas[0].Orders) == 1 &&
+			snapshot.Baristas[0].Orders[0].Status == Brewing
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "Barista-0", snapshot.Baristas[0].Name)
+	assert.Equal(t, OrderSnapshot{ID: order.ID, Customer: "test", Item: "Regular Coffee", Status: Brewing},
+		snapshot.Baristas[0].Orders[0])
+	assert.Empty(t, snapshot.Queued)
+	assert.Equal(t, 1, snapshot.KiosksFree)
+	assert.True(t, snapshot.Brewers[0].InUse)
+	assert.False(t, snapshot.Closed)
+
+	shop.Close()
+	snapshot = shop.Snapshot()
+	assert.True(t, snapshot.Closed)
+	assert.Empty(t, snapshot.Baristas[0].Orders)
+	assert.False(t, snapshot.Brewers[0].InUse)
+	assert.Equal(t, 1, snapshot.Stats.OrdersServed)
+}
+
+func TestSnapshotWhileClosing(t *testing.T) {
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers())
+
+	// a dashboard keeps reading the shop while it closes,
+	// run with -race to see they don't collide
+	done := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				shop.Snapshot()
+			}
+		}
+	}()
+
+	shop.Close()
+	close(done)
+	<-read
+	assert.True(t, shop.Snapshot().Closed)
+	assert.Equal(t, shop.Stats().OpenTime, shop.Snapshot().Stats.OpenTime)
+}
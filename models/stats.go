@@ -0,0 +1,281 @@
+package models
+
+import (
+	"blreynolds4/coffeeshop/units"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats is the report of how the shop did
+type Stats struct {
+	OrdersServed int
+	TotalWait    time.Duration
+	MaxWait      time.Duration
+	// the wait of every order served
+	Waits            []time.Duration
+	BatchesBrewed    int
+	CarafeCupsPoured int
+	CarafeCupsWasted int
+	OpenTime         time.Duration
+	BrewerSlots      []SlotUsage
+	// the quality score of every coffee served
+	QualityScores []float64
+	// wrong drinks caught by QA and what they cost
+	Remakes            int
+	RemakeWastedBeans  units.Grams
+	RemakeWastedCoffee units.Milliliters
+	RemakeDelay        time.Duration
+	// time baristas were clocked in and orders they handed
+	// off when they left
+	StaffedTime time.Duration
+	Handoffs    int
+	// baristas the autoscaler added and sent home
+	BaristasAdded   int
+	BaristasRemoved int
+	// orders cancelled while they were being made
+	Cancelled int
+	// customers who left because their item was sold out
+	TurnedAway int
+}
+
+func (s Stats) AverageWait() time.Duration {
+	if s.OrdersServed == 0 {
+		return 0
+	}
+
+	return s.TotalWait / time.Duration(s.OrdersServed)
+}
+
+// WastePercent is the share of carafe cups that went stale
+func (s Stats) WastePercent() float64 {
+	total := s.CarafeCupsPoured + s.CarafeCupsWasted
+	if total == 0 {
+		return 0
+	}
+
+	return 100 * float64(s.CarafeCupsWasted) / float64(total)
+}
+
+// WaitPercentile is the wait that p percent of the orders
+// served were within, by the nearest rank
+func (s Stats) WaitPercentile(p float64) time.Duration {
+	return Percentile(s.Waits, p)
+}
+
+// Percentile is the duration that p percent of the durations
+// are within, by the nearest rank
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+// StaffedHours is the simulated hours baristas were clocked in
+func (s Stats) StaffedHours() float64 {
+	return SimSeconds(s.StaffedTime) / 3600
+}
+
+func (s Stats) OrdersPerLaborHour() float64 {
+	if s.StaffedTime == 0 {
+		return 0
+	}
+
+	return float64(s.OrdersServed) / s.StaffedHours()
+}
+
+func (s Stats) AverageQuality() float64 {
+	if len(s.QualityScores) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, score := range s.QualityScores {
+		total += score
+	}
+
+	return total / float64(len(s.QualityScores))
+}
+
+// QualityDistribution counts the scores in buckets of 20,
+// the last bucket includes a perfect 100
+func (s Stats) QualityDistribution() [5]int {
+	result := [5]int{}
+	for _, score := range s.QualityScores {
+		bucket := int(score / 20)
+		if bucket > 4 {
+			bucket = 4
+		}
+		if bucket < 0 {
+			bucket = 0
+		}
+		result[bucket] += 1
+	}
+
+	return result
+}
+
+// SlotUtilization is the percent of the open time a brewer slot was busy
+func (s Stats) SlotUtilization(slot SlotUsage) float64 {
+	if s.OpenTime == 0 {
+		return 0
+	}
+
+	return 100 * float64(slot.Busy) / float64(s.OpenTime)
+}
+
+func (s Stats) String() string {
+	report := &strings.Builder{}
+	fmt.Fprintln(report, "Orders served", s.OrdersServed)
+	fmt.Fprintln(report, "Avg wait", s.AverageWait(), "Max wait", s.MaxWait)
+	if s.Cancelled > 0 {
+		fmt.Fprintln(report, "Cancelled", s.Cancelled)
+	}
+	if s.TurnedAway > 0 {
+		fmt.Fprintln(report, "Turned away sold out", s.TurnedAway)
+	}
+	if s.BatchesBrewed > 0 {
+		fmt.Fprintln(report, "Batches brewed", s.BatchesBrewed)
+		fmt.Fprintf(report, "Carafe cups poured %d wasted %d (%.1f%% waste)\n",
+			s.CarafeCupsPoured, s.CarafeCupsWasted, s.WastePercent())
+	}
+	if s.Remakes > 0 {
+		fmt.Fprintf(report, "Remakes %d wasted %s beans and %s coffee, delaying orders %s\n",
+			s.Remakes, s.RemakeWastedBeans, s.RemakeWastedCoffee, s.RemakeDelay)
+	}
+	if s.StaffedTime > 0 {
+		fmt.Fprintf(report, "Staffed hours %.2f, %.1f orders per labor hour, %d handoffs\n",
+			s.StaffedHours(), s.OrdersPerLaborHour(), s.Handoffs)
+	}
+	if s.BaristasAdded > 0 || s.BaristasRemoved > 0 {
+		fmt.Fprintf(report, "Autoscaler added %d and sent home %d baristas\n", s.BaristasAdded, s.BaristasRemoved)
+	}
+	if len(s.QualityScores) > 0 {
+		fmt.Fprintf(report, "Quality avg %.1f\n", s.AverageQuality())
+		// bars are scaled to the full count of coffees
+		for i, count := range s.QualityDistribution() {
+			bar := strings.Repeat("#", count*40/len(s.QualityScores))
+			fmt.Fprintf(report, "  %3d-%-3d %4d %s\n", i*20, (i+1)*20, count, bar)
+		}
+	}
+	for _, slot := range s.BrewerSlots {
+		fmt.Fprintf(report, "%s slot %d busy %.1f%%\n", slot.Brewer, slot.Slot, s.SlotUtilization(slot))
+	}
+
+	return report.String()
+}
+
+// statsRecorder is shared by everything in the shop
+// that has something to report
+type statsRecorder struct {
+	lock  sync.Mutex
+	stats Stats
+}
+
+func newStatsRecorder() *statsRecorder {
+	return &statsRecorder{}
+}
+
+func (sr *statsRecorder) snapshot() Stats {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	result := sr.stats
+	result.Waits = append([]time.Duration{}, sr.stats.Waits...)
+	result.QualityScores = append([]float64{}, sr.stats.QualityScores...)
+	return result
+}
+
+func (sr *statsRecorder) orderServed(o *Order) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	wait := time.Since(o.orderedAt)
+	sr.stats.OrdersServed += 1
+	sr.stats.TotalWait += wait
+	sr.stats.Waits = append(sr.stats.Waits, wait)
+	if wait > sr.stats.MaxWait {
+		sr.stats.MaxWait = wait
+	}
+	if !o.remadeAt.IsZero() {
+		sr.stats.RemakeDelay += time.Since(o.remadeAt)
+	}
+}
+
+func (sr *statsRecorder) remade(o *Order, wrong *Coffee) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.Remakes += 1
+	sr.stats.RemakeWastedBeans += o.GroundBeans.weight
+	sr.stats.RemakeWastedCoffee += wrong.volume
+}
+
+func (sr *statsRecorder) orderCancelled() {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.Cancelled += 1
+}
+
+func (sr *statsRecorder) turnedAway() {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.TurnedAway += 1
+}
+
+func (sr *statsRecorder) batchBrewed() {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.BatchesBrewed += 1
+}
+
+func (sr *statsRecorder) cupsPoured(cups int) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.CarafeCupsPoured += cups
+}
+
+func (sr *statsRecorder) cupsWasted(cups int) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.CarafeCupsWasted += cups
+}
+
+func (sr *statsRecorder) coffeeServed(c *Coffee) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.stats.QualityScores = append(sr.stats.QualityScores, c.quality.Score)
+}
+
+// baristasScaled records the autoscaler adding (1) or removing (-1) a barista
+func (sr *statsRecorder) baristasScaled(change int) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if change > 0 {
+		sr.stats.BaristasAdded += change
+	} else {
+		sr.stats.BaristasRemoved -= change
+	}
+}
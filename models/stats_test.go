@@ -0,0 +1,90 @@
+package models
+
+import (
+	"blreynolds4/coffeeshop/units"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsWaitAndWaste(t *testing.T) {
+	sr := newStatsRecorder()
+
+	o := NewOrder("customer", getTestMenuItem())
+	o.orderedAt = time.Now().Add(-time.Second)
+	sr.orderServed(o)
+	sr.cupsPoured(3)
+	sr.cupsWasted(1)
+	sr.batchBrewed()
+
+	stats := sr.snapshot()
+	assert.Equal(t, 1, stats.OrdersServed)
+	assert.InDelta(t, time.Second.Milliseconds(), stats.AverageWait().Milliseconds(), 5)
+	assert.Equal(t, stats.MaxWait, stats.TotalWait)
+	assert.InDelta(t, 25, stats.WastePercent(), 0.01)
+	assert.Contains(t, stats.String(), "25.0% waste")
+
+	// nothing served is no wait
+	assert.Equal(t, time.Duration(0), Stats{}.AverageWait())
+}
+
+func TestSlotUtilization(t *testing.T) {
+	stats := Stats{
+		OpenTime:    time.Second,
+		BrewerSlots: []SlotUsage{{Brewer: "Drip-0", Slot: 1, Busy: 250 * time.Millisecond}},
+	}
+
+	assert.InDelta(t, 25, stats.SlotUtilization(stats.BrewerSlots[0]), 0.01)
+	assert.Contains(t, stats.String(), "Drip-0 slot 1 busy 25.0%")
+}
+
+func TestQualityDistribution(t *testing.T) {
+	stats := Stats{QualityScores: []float64{10, 50, 55, 95, 100}}
+
+	assert.Equal(t, [5]int{1, 0, 2, 0, 2}, stats.QualityDistribution())
+	assert.InDelta(t, 62, stats.AverageQuality(), 0.001)
+	assert.Contains(t, stats.String(), "Quality avg 62.0")
+}
+
+func TestWaitPercentile(t *testing.T) {
+	stats := Stats{Waits: []time.Duration{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}}
+
+	assert.Equal(t, time.Duration(5), stats.WaitPercentile(50))
+	assert.Equal(t, time.Duration(10), stats.WaitPercentile(95))
+	assert.Equal(t, time.Duration(1), stats.WaitPercentile(0))
+	assert.Equal(t, time.Duration(10), stats.WaitPercentile(100))
+	assert.Equal(t, time.Duration(0), Stats{}.WaitPercentile(95))
+	// the waits are left in the order they were served
+	assert.Equal(t, time.Duration(5), stats.Waits[0])
+}
+
+func TestStatsRemakes(t *testing.T) {
+	sr := newStatsRecorder()
+
+	o := NewOrder("customer", getTestMenuItem())
+	o.GroundBeans = NewBeans(10, getTestVariety())
+	sr.remade(o, NewCoffee(180, o.GroundBeans, &MockBrewer{}))
+
+	// the delay is from the first remake to being served
+	o.remadeAt = time.Now().Add(-time.Second)
+	sr.orderServed(o)
+
+	stats := sr.snapshot()
+	assert.Equal(t, 1, stats.Remakes)
+	assert.Equal(t, units.Grams(10), stats.RemakeWastedBeans)
+	assert.Equal(t, units.Milliliters(180), stats.RemakeWastedCoffee)
+	assert.InDelta(t, time.Second.Milliseconds(), stats.RemakeDelay.Milliseconds(), 5)
+	assert.Contains(t, stats.String(), "Remakes 1 wasted 10g beans and 180ml coffee")
+}
+
+func TestOrdersPerLaborHour(t *testing.T) {
+	stats := Stats{OrdersServed: 30, StaffedTime: 2 * 3600 * SimSecond, Handoffs: 2}
+
+	assert.InDelta(t, 2, stats.StaffedHours(), 0.001)
+	assert.InDelta(t, 15, stats.OrdersPerLaborHour(), 0.001)
+	assert.Contains(t, stats.String(), "Staffed hours 2.00, 15.0 orders per labor hour, 2 handoffs")
+
+	// nobody worked, nothing per hour
+	assert.Equal(t, 0.0, Stats{OrdersServed: 1}.OrdersPerLaborHour())
+}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// the names of an order's spans
+const (
+	OrderSpan       = "order"
+	KioskWaitSpan   = "kiosk_wait"
+	GrinderWaitSpan = "grinder_wait"
+	GrindSpan       = "grind"
+	BrewerWaitSpan  = "brewer_wait"
+	BrewSpan        = "brew"
+	ServeSpan       = "serve"
+)
+
+var lastSpanID int64
+
+// Span is a timed piece of the work on an order.  The order is the
+// root span and the trace ID is the order ID.
+type Span struct {
+	TraceID int64
+	SpanID  int64
+	// ParentID is 0 for the order's root span
+	ParentID   int64
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+}
+
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Tracer gets every span when it ends, it's called from the
+// barista goroutines so it has to be safe to share
+type Tracer interface {
+	Export(span Span)
+}
+
+// WithTracer traces every order placed at a kiosk, with more
+// than one tracer each gets every span
+func WithTracer(tracer Tracer) ShopOption {
+	return func(cs *coffeeShop) {
+		if cs.tracer != nil {
+			cs.tracer = tracers{cs.tracer, tracer}
+			return
+		}
+		cs.tracer = tracer
+	}
+}
+
+// tracers sends every span to each of them
+type tracers []Tracer
+
+func (t tracers) Export(span Span) {
+	for _, tracer := range t {
+		tracer.Export(span)
+	}
+}
+
+// activeSpan is a span that hasn't ended, it's started on one goroutine
+// and can be ended on another.  A nil span is an order that isn't traced.
+type activeSpan struct {
+	span  Span
+	trace *orderTrace
+}
+
+// end sends the span to the tracer, attributes are name and value pairs
+func (as *activeSpan) end(attributes ...string) {
+	if as == nil {
+		return
+	}
+
+	as.trace.lock.Lock()
+	defer as.trace.lock.Unlock()
+
+	if _, open := as.trace.open[as]; !open {
+		return
+	}
+	delete(as.trace.open, as)
+	as.span.End = time.Now()
+	setAttributes(as.span.Attributes, attributes)
+	as.trace.tracer.Export(as.span)
+}
+
+// orderTrace is the root span of an order and its spans
+// that haven't ended
+type orderTrace struct {
+	lock   *sync.Mutex
+	tracer Tracer
+	root   Span
+	open   map[*activeSpan]bool
+	ended  bool
+}
+
+// newOrderTrace starts the order's root span at when the
+// customer started waiting for a kiosk
+func newOrderTrace(tracer Tracer, order *Order, waitStart time.Time) *orderTrace {
+	if tracer == nil {
+		return nil
+	}
+
+	result := &orderTrace{
+		lock:   &sync.Mutex{},
+		tracer: tracer,
+		root: Span{
+			TraceID: order.ID,
+			SpanID:  atomic.AddInt64(&lastSpanID, 1),
+			Name:    OrderSpan,
+			Start:   waitStart,
+			Attributes: map[string]string{
+				"customer": order.Customer,
+				"item":     order.Item.Name,
+			},
+		},
+		open: map[*activeSpan]bool{},
+	}
+
+	kioskWait := result.start(KioskWaitSpan)
+	kioskWait.span.Start = waitStart
+	kioskWait.end()
+
+	return result
+}
+
+// start a child span of the order, attributes are name and value pairs
+func (ot *orderTrace) start(name string, attributes ...string) *activeSpan {
+	if ot == nil {
+		return nil
+	}
+
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	if ot.ended {
+		return nil
+	}
+
+	result := &activeSpan{
+		span: Span{
+			TraceID:    ot.root.TraceID,
+			SpanID:     atomic.AddInt64(&lastSpanID, 1),
+			ParentID:   ot.root.SpanID,
+			Name:       name,
+			Start:      time.Now(),
+			Attributes: map[string]string{},
+		},
+		trace: ot,
+	}
+	setAttributes(result.span.Attributes, attributes)
+	ot.open[result] = true
+
+	return result
+}
+
+// finish ends the spans still open then the order's root span
+func (ot *orderTrace) finish(status OrderStatus) {
+	if ot == nil {
+		return
+	}
+
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	if ot.ended {
+		return
+	}
+	ot.ended = true
+
+	now := time.Now()
+	for as := range ot.open {
+		as.span.End = now
+		as.span.Attributes["status"] = metricName(status)
+		ot.tracer.Export(as.span)
+	}
+	ot.open = map[*activeSpan]bool{}
+
+	ot.root.End = now
+	ot.root.Attributes["status"] = metricName(status)
+	ot.tracer.Export(ot.root)
+}
+
+func setAttributes(to map[string]string, attributes []string) {
+	for i := 0; i+1 < len(attributes); i += 2 {
+		to[attributes[i]] = attributes[i+1]
+	}
+}
+
+// jsonExporter writes each span as a line of JSON
+type jsonExporter struct {
+	lock *sync.Mutex
+	out  io.Writer
+}
+
+// NewJSONExporter writes spans to a file or stdout as JSON lines
+// with their times in simulated seconds since the span started
+func NewJSONExporter(out io.Writer) Tracer {
+	return &jsonExporter{
+		lock: &sync.Mutex{},
+		out:  out,
+	}
+}
+
+type jsonSpan struct {
+	TraceID    int64             `json:"trace_id"`
+	SpanID     int64             `json:"span_id"`
+	ParentID   int64             `json:"parent_id,omitempty"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Seconds    float64           `json:"seconds"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func (je *jsonExporter) Export(span Span) {
+	line, err := json.Marshal(jsonSpan{
+		TraceID:    span.TraceID,
+		SpanID:     span.SpanID,
+		ParentID:   span.ParentID,
+		Name:       span.Name,
+		Start:      span.Start,
+		End:        span.End,
+		Seconds:    SimSeconds(span.Duration()),
+		Attributes: span.Attributes,
+	})
+	if err != nil {
+		fmt.Println("Error exporting span", err)
+		return
+	}
+
+	je.lock.Lock()
+	defer je.lock.Unlock()
+
+	if _, err := fmt.Fprintf(je.out, "%s\n", line); err != nil {
+		fmt.Println("Error exporting span", err)
+	}
+}
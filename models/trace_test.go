@@ -0,0 +1,142 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// spanRecorder keeps the spans by name
+type spanRecorder struct {
+	lock  *sync.Mutex
+	spans map[string][]Span
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{lock: &sync.Mutex{}, spans: map[string][]Span{}}
+}
+
+func (sr *spanRecorder) Export(span Span) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.spans[span.Name] = append(sr.spans[span.Name], span)
+}
+
+func TestOrderSpans(t *testing.T) {
+	recorder := newSpanRecorder()
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers(),
+		WithTracer(recorder))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+	shop.Close()
+
+	assert.Len(t, recorder.spans[OrderSpan], 1)
+	root := recorder.spans[OrderSpan][0]
+	assert.Equal(t, order.ID, root.TraceID)
+	assert.Equal(t, int64(0), root.ParentID)
+	assert.Equal(t, "test", root.Attributes["customer"])
+	assert.Equal(t, "complete", root.Attributes["status"])
+
+	// every step is a child of the order, in order, tagged
+	// with who did it on what
+	last := root.Start
+	for _, name := range []string{KioskWaitSpan, GrinderWaitSpan, GrindSpan, BrewerWaitSpan, BrewSpan, ServeSpan} {
+		assert.Len(t, recorder.spans[name], 1, name)
+		span := recorder.spans[name][0]
+		assert.Equal(t, root.TraceID, span.TraceID)
+		assert.Equal(t, root.SpanID, span.ParentID)
+		assert.False(t, span.Start.Before(last), name)
+		assert.False(t, span.End.After(root.End), name)
+		last = span.End
+		if name != KioskWaitSpan {
+			assert.Equal(t, "Barista-0", span.Attributes["barista"], name)
+		}
+	}
+	assert.Equal(t, "Grinder-0", recorder.spans[GrindSpan][0].Attributes["machine"])
+	assert.Equal(t, "Grinder-0", recorder.spans[GrinderWaitSpan][0].Attributes["machine"])
+	assert.Equal(t, "Drip-0", recorder.spans[BrewSpan][0].Attributes["machine"])
+}
+
+func TestMoreThanOneTracer(t *testing.T) {
+	first := newSpanRecorder()
+	second := newSpanRecorder()
+	shop := NewCoffeeShop(Menu{getTestMenuItem()},
+		1, // ordering kiosk count
+		1, // barista count
+		1, // max orders per barista
+		getTestGrinders(),
+		getTestBrewers(),
+		WithTracer(first),
+		WithTracer(second))
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", getTestMenuItem())
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+	shop.Close()
+
+	assert.Len(t, first.spans[OrderSpan], 1)
+	assert.Equal(t, first.spans, second.spans)
+}
+
+func TestCancelledOrderSpans(t *testing.T) {
+	recorder := newSpanRecorder()
+	order := NewOrder("test", getTestMenuItem())
+	order.trace = newOrderTrace(recorder, order, time.Now())
+
+	wait := order.trace.start(GrinderWaitSpan, "barista", "test")
+	assert.True(t, order.Cancel())
+
+	// the open span ends with the order and isn't sent again
+	wait.end()
+	assert.Len(t, recorder.spans[GrinderWaitSpan], 1)
+	assert.Equal(t, "cancelled", recorder.spans[GrinderWaitSpan][0].Attributes["status"])
+	assert.Equal(t, "cancelled", recorder.spans[OrderSpan][0].Attributes["status"])
+
+	// nothing starts after it's done
+	assert.Nil(t, order.trace.start(BrewerWaitSpan))
+}
+
+func TestUntracedOrder(t *testing.T) {
+	order := NewOrder("test", getTestMenuItem())
+
+	// all the span calls are safe without a tracer
+	span := order.trace.start(GrindSpan)
+	assert.Nil(t, span)
+	span.end()
+	order.trace.finish(Complete)
+}
+
+func TestJSONExporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	start := time.Now()
+	NewJSONExporter(out).Export(Span{
+		TraceID:    1,
+		SpanID:     2,
+		ParentID:   1,
+		Name:       GrindSpan,
+		Start:      start,
+		End:        start.Add(3 * SimSecond),
+		Attributes: map[string]string{"machine": "Grinder-0"},
+	})
+
+	line := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, GrindSpan, line["name"])
+	assert.Equal(t, 3.0, line["seconds"])
+	assert.Equal(t, map[string]interface{}{"machine": "Grinder-0"}, line["attributes"])
+	assert.Equal(t, byte('\n'), out.Bytes()[out.Len()-1])
+}
@@ -0,0 +1,139 @@
+// Package queueing predicts how busy a stage of servers is and how long
+// jobs wait for one from queueing theory, to check the simulation against
+package queueing
+
+import "math"
+
+// Station is a stage with servers working through jobs that arrive
+// at random, a Poisson process
+type Station struct {
+	Name    string
+	Servers int
+	// ArrivalRate is jobs a second
+	ArrivalRate float64
+	// MeanService is the seconds a server works on a job
+	MeanService float64
+	// ServiceSCV is the squared coefficient of variation of the
+	// service time, its variance over its mean squared.  It's 1
+	// for exponential service times and 0 for fixed ones.
+	ServiceSCV float64
+}
+
+// Prediction is a station's long run behavior
+type Prediction struct {
+	// Utilization is the share of the time the servers are busy
+	Utilization float64
+	// WaitProbability is the chance a job waits for a server
+	WaitProbability float64
+	// Wait is the mean seconds a job waits for a server,
+	// infinite when the station can't keep up
+	Wait float64
+}
+
+// Stable is true when the servers can keep up with the jobs
+func (p Prediction) Stable() bool {
+	return p.Utilization < 1
+}
+
+// Load is the offered load, the servers the jobs would keep busy
+func (s Station) Load() float64 {
+	return s.ArrivalRate * s.MeanService
+}
+
+// MMc predicts the station as if its service times were
+// exponential, the Erlang C model
+func (s Station) MMc() Prediction {
+	result := Prediction{}
+	if s.Servers < 1 || s.MeanService <= 0 {
+		return result
+	}
+
+	load := s.Load()
+	result.Utilization = load / float64(s.Servers)
+	if !result.Stable() {
+		result.WaitProbability = 1
+		result.Wait = math.Inf(1)
+		return result
+	}
+
+	result.WaitProbability = ErlangC(s.Servers, load)
+	serviceRate := 1 / s.MeanService
+	result.Wait = result.WaitProbability / (float64(s.Servers)*serviceRate - s.ArrivalRate)
+	return result
+}
+
+// MGc predicts the station with its service time's variation by the
+// Allen-Cunneen approximation, the M/M/c wait scaled by (1 + SCV) / 2.
+// It's exact for a single server, the Pollaczek-Khinchine formula.
+func (s Station) MGc() Prediction {
+	result := s.MMc()
+	if result.Stable() {
+		result.Wait *= (1 + s.ServiceSCV) / 2
+	}
+
+	return result
+}
+
+// ErlangC is the chance a job waits with the servers
+// and offered load, the load has to be under the servers
+func ErlangC(servers int, load float64) float64 {
+	if load <= 0 {
+		return 0
+	}
+	if load >= float64(servers) {
+		return 1
+	}
+
+	// sum the terms of the Poisson distribution up to the
+	// servers, each term from the last keeps them from overflowing
+	term := 1.0
+	sum := 0.0
+	for k := 0; k < servers; k++ {
+		sum += term
+		term *= load / float64(k+1)
+	}
+	waiting := term * float64(servers) / (float64(servers) - load)
+
+	return waiting / (sum + waiting)
+}
+
+// ServiceMix builds up a service time from the kinds of jobs a
+// station sees, each with its share of the jobs
+type ServiceMix struct {
+	weight float64
+	// the weighted first and second moments
+	first  float64
+	second float64
+}
+
+// Add a kind of job with its share, mean service time and SCV
+func (sm *ServiceMix) Add(weight float64, mean float64, scv float64) {
+	sm.AddMoments(weight, mean, mean*mean*(1+scv))
+}
+
+// AddMoments adds a kind of job by its service time's first
+// and second moments, its mean and mean square
+func (sm *ServiceMix) AddMoments(weight float64, mean float64, meanSquare float64) {
+	sm.weight += weight
+	sm.first += weight * mean
+	sm.second += weight * meanSquare
+}
+
+// Mean is the mean service time over all the jobs
+func (sm *ServiceMix) Mean() float64 {
+	if sm.weight == 0 {
+		return 0
+	}
+
+	return sm.first / sm.weight
+}
+
+// SCV is the squared coefficient of variation over all the jobs
+func (sm *ServiceMix) SCV() float64 {
+	mean := sm.Mean()
+	if mean == 0 {
+		return 0
+	}
+
+	return (sm.second/sm.weight)/(mean*mean) - 1
+}
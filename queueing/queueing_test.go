@@ -0,0 +1,62 @@
+package queueing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErlangC(t *testing.T) {
+	// two servers at half load wait a third of the time
+	assert.InDelta(t, 1.0/3, ErlangC(2, 1), 1e-9)
+	// a single server waits as often as it's busy
+	assert.InDelta(t, 0.7, ErlangC(1, 0.7), 1e-9)
+	assert.Equal(t, 0.0, ErlangC(3, 0))
+	assert.Equal(t, 1.0, ErlangC(3, 3))
+	// lots of servers don't overflow
+	assert.InDelta(t, 0.0, ErlangC(500, 100), 1e-9)
+}
+
+func TestMMc(t *testing.T) {
+	// M/M/1 at half load waits rho / (mu - lambda)
+	single := Station{Servers: 1, ArrivalRate: 0.5, MeanService: 1, ServiceSCV: 1}
+	prediction := single.MMc()
+	assert.InDelta(t, 0.5, prediction.Utilization, 1e-9)
+	assert.InDelta(t, 1, prediction.Wait, 1e-9)
+	assert.True(t, prediction.Stable())
+
+	double := Station{Servers: 2, ArrivalRate: 1, MeanService: 1, ServiceSCV: 1}
+	prediction = double.MMc()
+	assert.InDelta(t, 0.5, prediction.Utilization, 1e-9)
+	assert.InDelta(t, 1.0/3, prediction.WaitProbability, 1e-9)
+	assert.InDelta(t, 1.0/3, prediction.Wait, 1e-9)
+
+	overloaded := Station{Servers: 1, ArrivalRate: 2, MeanService: 1}
+	prediction = overloaded.MMc()
+	assert.False(t, prediction.Stable())
+	assert.True(t, math.IsInf(prediction.Wait, 1))
+}
+
+func TestMGc(t *testing.T) {
+	// fixed service times wait half as long as exponential ones
+	fixed := Station{Servers: 1, ArrivalRate: 0.5, MeanService: 1, ServiceSCV: 0}
+	assert.InDelta(t, 0.5, fixed.MGc().Wait, 1e-9)
+	assert.InDelta(t, 0.5, fixed.MGc().Utilization, 1e-9)
+
+	exponential := Station{Servers: 3, ArrivalRate: 2, MeanService: 1, ServiceSCV: 1}
+	assert.InDelta(t, exponential.MMc().Wait, exponential.MGc().Wait, 1e-9)
+}
+
+func TestServiceMix(t *testing.T) {
+	mix := ServiceMix{}
+	assert.Equal(t, 0.0, mix.Mean())
+	assert.Equal(t, 0.0, mix.SCV())
+
+	// half take 1 second and half take 3, fixed
+	mix.Add(1, 1, 0)
+	mix.Add(1, 3, 0)
+	assert.InDelta(t, 2, mix.Mean(), 1e-9)
+	// variance 1 over a mean squared of 4
+	assert.InDelta(t, 0.25, mix.SCV(), 1e-9)
+}
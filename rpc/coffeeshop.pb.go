@@ -0,0 +1,1079 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: coffeeshop.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OrderStatus int32
+
+const (
+	OrderStatus_ORDER_STATUS_UNSPECIFIED    OrderStatus = 0
+	OrderStatus_ORDER_STATUS_ORDERED        OrderStatus = 1
+	OrderStatus_ORDER_STATUS_READY_TO_GRIND OrderStatus = 2
+	OrderStatus_ORDER_STATUS_GRINDING       OrderStatus = 3
+	OrderStatus_ORDER_STATUS_READY_TO_BREW  OrderStatus = 4
+	OrderStatus_ORDER_STATUS_BREWING        OrderStatus = 5
+	OrderStatus_ORDER_STATUS_COMPLETE       OrderStatus = 6
+	OrderStatus_ORDER_STATUS_CANCELLED      OrderStatus = 7
+)
+
+// Enum value maps for OrderStatus.
+var (
+	OrderStatus_name = map[int32]string{
+		0: "ORDER_STATUS_UNSPECIFIED",
+		1: "ORDER_STATUS_ORDERED",
+		2: "ORDER_STATUS_READY_TO_GRIND",
+		3: "ORDER_STATUS_GRINDING",
+		4: "ORDER_STATUS_READY_TO_BREW",
+		5: "ORDER_STATUS_BREWING",
+		6: "ORDER_STATUS_COMPLETE",
+		7: "ORDER_STATUS_CANCELLED",
+	}
+	OrderStatus_value = map[string]int32{
+		"ORDER_STATUS_UNSPECIFIED":    0,
+		"ORDER_STATUS_ORDERED":        1,
+		"ORDER_STATUS_READY_TO_GRIND": 2,
+		"ORDER_STATUS_GRINDING":       3,
+		"ORDER_STATUS_READY_TO_BREW":  4,
+		"ORDER_STATUS_BREWING":        5,
+		"ORDER_STATUS_COMPLETE":       6,
+		"ORDER_STATUS_CANCELLED":      7,
+	}
+)
+
+func (x OrderStatus) Enum() *OrderStatus {
+	p := new(OrderStatus)
+	*p = x
+	return p
+}
+
+func (x OrderStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_coffeeshop_proto_enumTypes[0].Descriptor()
+}
+
+func (OrderStatus) Type() protoreflect.EnumType {
+	return &file_coffeeshop_proto_enumTypes[0]
+}
+
+func (x OrderStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderStatus.Descriptor instead.
+func (OrderStatus) EnumDescriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{0}
+}
+
+type OrderEvent_Kind int32
+
+const (
+	OrderEvent_KIND_UNSPECIFIED OrderEvent_Kind = 0
+	// the order moved to a new status
+	OrderEvent_KIND_STATUS OrderEvent_Kind = 1
+	// the barista got to the next step of the order
+	OrderEvent_KIND_STEP OrderEvent_Kind = 2
+)
+
+// Enum value maps for OrderEvent_Kind.
+var (
+	OrderEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_STATUS",
+		2: "KIND_STEP",
+	}
+	OrderEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_STATUS":      1,
+		"KIND_STEP":        2,
+	}
+)
+
+func (x OrderEvent_Kind) Enum() *OrderEvent_Kind {
+	p := new(OrderEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x OrderEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_coffeeshop_proto_enumTypes[1].Descriptor()
+}
+
+func (OrderEvent_Kind) Type() protoreflect.EnumType {
+	return &file_coffeeshop_proto_enumTypes[1]
+}
+
+func (x OrderEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderEvent_Kind.Descriptor instead.
+func (OrderEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{8, 0}
+}
+
+type GetMenuRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMenuRequest) Reset() {
+	*x = GetMenuRequest{}
+	mi := &file_coffeeshop_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMenuRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMenuRequest) ProtoMessage() {}
+
+func (x *GetMenuRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMenuRequest.ProtoReflect.Descriptor instead.
+func (*GetMenuRequest) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{0}
+}
+
+type MenuItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SizeMl        float64                `protobuf:"fixed64,2,opt,name=size_ml,json=sizeMl,proto3" json:"size_ml,omitempty"`
+	Ratio         float64                `protobuf:"fixed64,3,opt,name=ratio,proto3" json:"ratio,omitempty"`
+	Variety       string                 `protobuf:"bytes,4,opt,name=variety,proto3" json:"variety,omitempty"`
+	Methods       []string               `protobuf:"bytes,5,rep,name=methods,proto3" json:"methods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MenuItem) Reset() {
+	*x = MenuItem{}
+	mi := &file_coffeeshop_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MenuItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MenuItem) ProtoMessage() {}
+
+func (x *MenuItem) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MenuItem.ProtoReflect.Descriptor instead.
+func (*MenuItem) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MenuItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MenuItem) GetSizeMl() float64 {
+	if x != nil {
+		return x.SizeMl
+	}
+	return 0
+}
+
+func (x *MenuItem) GetRatio() float64 {
+	if x != nil {
+		return x.Ratio
+	}
+	return 0
+}
+
+func (x *MenuItem) GetVariety() string {
+	if x != nil {
+		return x.Variety
+	}
+	return ""
+}
+
+func (x *MenuItem) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+type Menu struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*MenuItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Menu) Reset() {
+	*x = Menu{}
+	mi := &file_coffeeshop_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Menu) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Menu) ProtoMessage() {}
+
+func (x *Menu) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Menu.ProtoReflect.Descriptor instead.
+func (*Menu) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Menu) GetItems() []*MenuItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PlaceOrderRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Customer string                 `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+	// the name of the menu item
+	Item          string `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceOrderRequest) Reset() {
+	*x = PlaceOrderRequest{}
+	mi := &file_coffeeshop_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceOrderRequest) ProtoMessage() {}
+
+func (x *PlaceOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceOrderRequest.ProtoReflect.Descriptor instead.
+func (*PlaceOrderRequest) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlaceOrderRequest) GetCustomer() string {
+	if x != nil {
+		return x.Customer
+	}
+	return ""
+}
+
+func (x *PlaceOrderRequest) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+type StatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        OrderStatus            `protobuf:"varint,1,opt,name=status,proto3,enum=coffeeshop.v1.OrderStatus" json:"status,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusChange) Reset() {
+	*x = StatusChange{}
+	mi := &file_coffeeshop_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusChange) ProtoMessage() {}
+
+func (x *StatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusChange.ProtoReflect.Descriptor instead.
+func (*StatusChange) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StatusChange) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *StatusChange) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type Coffee struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VolumeMl      float64                `protobuf:"fixed64,1,opt,name=volume_ml,json=volumeMl,proto3" json:"volume_ml,omitempty"`
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Variety       string                 `protobuf:"bytes,4,opt,name=variety,proto3" json:"variety,omitempty"`
+	BrewedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=brewed_at,json=brewedAt,proto3" json:"brewed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coffee) Reset() {
+	*x = Coffee{}
+	mi := &file_coffeeshop_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coffee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coffee) ProtoMessage() {}
+
+func (x *Coffee) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coffee.ProtoReflect.Descriptor instead.
+func (*Coffee) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Coffee) GetVolumeMl() float64 {
+	if x != nil {
+		return x.VolumeMl
+	}
+	return 0
+}
+
+func (x *Coffee) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Coffee) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Coffee) GetVariety() string {
+	if x != nil {
+		return x.Variety
+	}
+	return ""
+}
+
+func (x *Coffee) GetBrewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BrewedAt
+	}
+	return nil
+}
+
+type Order struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Customer string                 `protobuf:"bytes,2,opt,name=customer,proto3" json:"customer,omitempty"`
+	Item     string                 `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`
+	Status   OrderStatus            `protobuf:"varint,4,opt,name=status,proto3,enum=coffeeshop.v1.OrderStatus" json:"status,omitempty"`
+	Timeline []*StatusChange        `protobuf:"bytes,5,rep,name=timeline,proto3" json:"timeline,omitempty"`
+	// the coffee once it's made
+	Coffee        *Coffee `protobuf:"bytes,6,opt,name=coffee,proto3" json:"coffee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_coffeeshop_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Order) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Order) GetCustomer() string {
+	if x != nil {
+		return x.Customer
+	}
+	return ""
+}
+
+func (x *Order) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+func (x *Order) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *Order) GetTimeline() []*StatusChange {
+	if x != nil {
+		return x.Timeline
+	}
+	return nil
+}
+
+func (x *Order) GetCoffee() *Coffee {
+	if x != nil {
+		return x.Coffee
+	}
+	return nil
+}
+
+type WatchOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOrderRequest) Reset() {
+	*x = WatchOrderRequest{}
+	mi := &file_coffeeshop_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrderRequest) ProtoMessage() {}
+
+func (x *WatchOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrderRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrderRequest) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchOrderRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type OrderEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Kind    OrderEvent_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=coffeeshop.v1.OrderEvent_Kind" json:"kind,omitempty"`
+	OrderId int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status  OrderStatus            `protobuf:"varint,3,opt,name=status,proto3,enum=coffeeshop.v1.OrderStatus" json:"status,omitempty"`
+	// the barista's step, like GrinderAvailable
+	Step    string                 `protobuf:"bytes,4,opt,name=step,proto3" json:"step,omitempty"`
+	Barista string                 `protobuf:"bytes,5,opt,name=barista,proto3" json:"barista,omitempty"`
+	At      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=at,proto3" json:"at,omitempty"`
+	// the whole order on the last event, with its coffee if it's made
+	Order         *Order `protobuf:"bytes,7,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_coffeeshop_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OrderEvent) GetKind() OrderEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return OrderEvent_KIND_UNSPECIFIED
+}
+
+func (x *OrderEvent) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderEvent) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *OrderEvent) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetBarista() string {
+	if x != nil {
+		return x.Barista
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_coffeeshop_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CancelOrderRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_coffeeshop_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{10}
+}
+
+type Stats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	OrdersServed       int64                  `protobuf:"varint,1,opt,name=orders_served,json=ordersServed,proto3" json:"orders_served,omitempty"`
+	Cancelled          int64                  `protobuf:"varint,2,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	AverageWaitSeconds float64                `protobuf:"fixed64,3,opt,name=average_wait_seconds,json=averageWaitSeconds,proto3" json:"average_wait_seconds,omitempty"`
+	MaxWaitSeconds     float64                `protobuf:"fixed64,4,opt,name=max_wait_seconds,json=maxWaitSeconds,proto3" json:"max_wait_seconds,omitempty"`
+	Remakes            int64                  `protobuf:"varint,5,opt,name=remakes,proto3" json:"remakes,omitempty"`
+	AverageQuality     float64                `protobuf:"fixed64,6,opt,name=average_quality,json=averageQuality,proto3" json:"average_quality,omitempty"`
+	BatchesBrewed      int64                  `protobuf:"varint,7,opt,name=batches_brewed,json=batchesBrewed,proto3" json:"batches_brewed,omitempty"`
+	CarafeCupsPoured   int64                  `protobuf:"varint,8,opt,name=carafe_cups_poured,json=carafeCupsPoured,proto3" json:"carafe_cups_poured,omitempty"`
+	CarafeCupsWasted   int64                  `protobuf:"varint,9,opt,name=carafe_cups_wasted,json=carafeCupsWasted,proto3" json:"carafe_cups_wasted,omitempty"`
+	StaffedHours       float64                `protobuf:"fixed64,10,opt,name=staffed_hours,json=staffedHours,proto3" json:"staffed_hours,omitempty"`
+	OrdersPerLaborHour float64                `protobuf:"fixed64,11,opt,name=orders_per_labor_hour,json=ordersPerLaborHour,proto3" json:"orders_per_labor_hour,omitempty"`
+	OpenSeconds        float64                `protobuf:"fixed64,12,opt,name=open_seconds,json=openSeconds,proto3" json:"open_seconds,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_coffeeshop_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_coffeeshop_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_coffeeshop_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Stats) GetOrdersServed() int64 {
+	if x != nil {
+		return x.OrdersServed
+	}
+	return 0
+}
+
+func (x *Stats) GetCancelled() int64 {
+	if x != nil {
+		return x.Cancelled
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageWaitSeconds() float64 {
+	if x != nil {
+		return x.AverageWaitSeconds
+	}
+	return 0
+}
+
+func (x *Stats) GetMaxWaitSeconds() float64 {
+	if x != nil {
+		return x.MaxWaitSeconds
+	}
+	return 0
+}
+
+func (x *Stats) GetRemakes() int64 {
+	if x != nil {
+		return x.Remakes
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageQuality() float64 {
+	if x != nil {
+		return x.AverageQuality
+	}
+	return 0
+}
+
+func (x *Stats) GetBatchesBrewed() int64 {
+	if x != nil {
+		return x.BatchesBrewed
+	}
+	return 0
+}
+
+func (x *Stats) GetCarafeCupsPoured() int64 {
+	if x != nil {
+		return x.CarafeCupsPoured
+	}
+	return 0
+}
+
+func (x *Stats) GetCarafeCupsWasted() int64 {
+	if x != nil {
+		return x.CarafeCupsWasted
+	}
+	return 0
+}
+
+func (x *Stats) GetStaffedHours() float64 {
+	if x != nil {
+		return x.StaffedHours
+	}
+	return 0
+}
+
+func (x *Stats) GetOrdersPerLaborHour() float64 {
+	if x != nil {
+		return x.OrdersPerLaborHour
+	}
+	return 0
+}
+
+func (x *Stats) GetOpenSeconds() float64 {
+	if x != nil {
+		return x.OpenSeconds
+	}
+	return 0
+}
+
+var File_coffeeshop_proto protoreflect.FileDescriptor
+
+const file_coffeeshop_proto_rawDesc = "" +
+	"\n" +
+	"\x10coffeeshop.proto\x12\rcoffeeshop.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x10\n" +
+	"\x0eGetMenuRequest\"\x81\x01\n" +
+	"\bMenuItem\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\asize_ml\x18\x02 \x01(\x01R\x06sizeMl\x12\x14\n" +
+	"\x05ratio\x18\x03 \x01(\x01R\x05ratio\x12\x18\n" +
+	"\avariety\x18\x04 \x01(\tR\avariety\x12\x18\n" +
+	"\amethods\x18\x05 \x03(\tR\amethods\"5\n" +
+	"\x04Menu\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.coffeeshop.v1.MenuItemR\x05items\"C\n" +
+	"\x11PlaceOrderRequest\x12\x1a\n" +
+	"\bcustomer\x18\x01 \x01(\tR\bcustomer\x12\x12\n" +
+	"\x04item\x18\x02 \x01(\tR\x04item\"n\n" +
+	"\fStatusChange\x122\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x1a.coffeeshop.v1.OrderStatusR\x06status\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"\xa4\x01\n" +
+	"\x06Coffee\x12\x1b\n" +
+	"\tvolume_ml\x18\x01 \x01(\x01R\bvolumeMl\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x12\x18\n" +
+	"\avariety\x18\x04 \x01(\tR\avariety\x127\n" +
+	"\tbrewed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bbrewedAt\"\xe3\x01\n" +
+	"\x05Order\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bcustomer\x18\x02 \x01(\tR\bcustomer\x12\x12\n" +
+	"\x04item\x18\x03 \x01(\tR\x04item\x122\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1a.coffeeshop.v1.OrderStatusR\x06status\x127\n" +
+	"\btimeline\x18\x05 \x03(\v2\x1b.coffeeshop.v1.StatusChangeR\btimeline\x12-\n" +
+	"\x06coffee\x18\x06 \x01(\v2\x15.coffeeshop.v1.CoffeeR\x06coffee\"#\n" +
+	"\x11WatchOrderRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xd3\x02\n" +
+	"\n" +
+	"OrderEvent\x122\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1e.coffeeshop.v1.OrderEvent.KindR\x04kind\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x122\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x1a.coffeeshop.v1.OrderStatusR\x06status\x12\x12\n" +
+	"\x04step\x18\x04 \x01(\tR\x04step\x12\x18\n" +
+	"\abarista\x18\x05 \x01(\tR\abarista\x12*\n" +
+	"\x02at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12*\n" +
+	"\x05order\x18\a \x01(\v2\x14.coffeeshop.v1.OrderR\x05order\"<\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vKIND_STATUS\x10\x01\x12\r\n" +
+	"\tKIND_STEP\x10\x02\"$\n" +
+	"\x12CancelOrderRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x11\n" +
+	"\x0fGetStatsRequest\"\xe7\x03\n" +
+	"\x05Stats\x12#\n" +
+	"\rorders_served\x18\x01 \x01(\x03R\fordersServed\x12\x1c\n" +
+	"\tcancelled\x18\x02 \x01(\x03R\tcancelled\x120\n" +
+	"\x14average_wait_seconds\x18\x03 \x01(\x01R\x12averageWaitSeconds\x12(\n" +
+	"\x10max_wait_seconds\x18\x04 \x01(\x01R\x0emaxWaitSeconds\x12\x18\n" +
+	"\aremakes\x18\x05 \x01(\x03R\aremakes\x12'\n" +
+	"\x0faverage_quality\x18\x06 \x01(\x01R\x0eaverageQuality\x12%\n" +
+	"\x0ebatches_brewed\x18\a \x01(\x03R\rbatchesBrewed\x12,\n" +
+	"\x12carafe_cups_poured\x18\b \x01(\x03R\x10carafeCupsPoured\x12,\n" +
+	"\x12carafe_cups_wasted\x18\t \x01(\x03R\x10carafeCupsWasted\x12#\n" +
+	"\rstaffed_hours\x18\n" +
+	" \x01(\x01R\fstaffedHours\x121\n" +
+	"\x15orders_per_labor_hour\x18\v \x01(\x01R\x12ordersPerLaborHour\x12!\n" +
+	"\fopen_seconds\x18\f \x01(\x01R\vopenSeconds*\xf2\x01\n" +
+	"\vOrderStatus\x12\x1c\n" +
+	"\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ORDER_STATUS_ORDERED\x10\x01\x12\x1f\n" +
+	"\x1bORDER_STATUS_READY_TO_GRIND\x10\x02\x12\x19\n" +
+	"\x15ORDER_STATUS_GRINDING\x10\x03\x12\x1e\n" +
+	"\x1aORDER_STATUS_READY_TO_BREW\x10\x04\x12\x18\n" +
+	"\x14ORDER_STATUS_BREWING\x10\x05\x12\x19\n" +
+	"\x15ORDER_STATUS_COMPLETE\x10\x06\x12\x1a\n" +
+	"\x16ORDER_STATUS_CANCELLED\x10\a2\xe8\x02\n" +
+	"\n" +
+	"CoffeeShop\x12=\n" +
+	"\aGetMenu\x12\x1d.coffeeshop.v1.GetMenuRequest\x1a\x13.coffeeshop.v1.Menu\x12D\n" +
+	"\n" +
+	"PlaceOrder\x12 .coffeeshop.v1.PlaceOrderRequest\x1a\x14.coffeeshop.v1.Order\x12K\n" +
+	"\n" +
+	"WatchOrder\x12 .coffeeshop.v1.WatchOrderRequest\x1a\x19.coffeeshop.v1.OrderEvent0\x01\x12F\n" +
+	"\vCancelOrder\x12!.coffeeshop.v1.CancelOrderRequest\x1a\x14.coffeeshop.v1.Order\x12@\n" +
+	"\bGetStats\x12\x1e.coffeeshop.v1.GetStatsRequest\x1a\x14.coffeeshop.v1.StatsB\x1cZ\x1ablreynolds4/coffeeshop/rpcb\x06proto3"
+
+var (
+	file_coffeeshop_proto_rawDescOnce sync.Once
+	file_coffeeshop_proto_rawDescData []byte
+)
+
+func file_coffeeshop_proto_rawDescGZIP() []byte {
+	file_coffeeshop_proto_rawDescOnce.Do(func() {
+		file_coffeeshop_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_coffeeshop_proto_rawDesc), len(file_coffeeshop_proto_rawDesc)))
+	})
+	return file_coffeeshop_proto_rawDescData
+}
+
+var file_coffeeshop_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_coffeeshop_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_coffeeshop_proto_goTypes = []any{
+	(OrderStatus)(0),              // 0: coffeeshop.v1.OrderStatus
+	(OrderEvent_Kind)(0),          // 1: coffeeshop.v1.OrderEvent.Kind
+	(*GetMenuRequest)(nil),        // 2: coffeeshop.v1.GetMenuRequest
+	(*MenuItem)(nil),              // 3: coffeeshop.v1.MenuItem
+	(*Menu)(nil),                  // 4: coffeeshop.v1.Menu
+	(*PlaceOrderRequest)(nil),     // 5: coffeeshop.v1.PlaceOrderRequest
+	(*StatusChange)(nil),          // 6: coffeeshop.v1.StatusChange
+	(*Coffee)(nil),                // 7: coffeeshop.v1.Coffee
+	(*Order)(nil),                 // 8: coffeeshop.v1.Order
+	(*WatchOrderRequest)(nil),     // 9: coffeeshop.v1.WatchOrderRequest
+	(*OrderEvent)(nil),            // 10: coffeeshop.v1.OrderEvent
+	(*CancelOrderRequest)(nil),    // 11: coffeeshop.v1.CancelOrderRequest
+	(*GetStatsRequest)(nil),       // 12: coffeeshop.v1.GetStatsRequest
+	(*Stats)(nil),                 // 13: coffeeshop.v1.Stats
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_coffeeshop_proto_depIdxs = []int32{
+	3,  // 0: coffeeshop.v1.Menu.items:type_name -> coffeeshop.v1.MenuItem
+	0,  // 1: coffeeshop.v1.StatusChange.status:type_name -> coffeeshop.v1.OrderStatus
+	14, // 2: coffeeshop.v1.StatusChange.at:type_name -> google.protobuf.Timestamp
+	14, // 3: coffeeshop.v1.Coffee.brewed_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: coffeeshop.v1.Order.status:type_name -> coffeeshop.v1.OrderStatus
+	6,  // 5: coffeeshop.v1.Order.timeline:type_name -> coffeeshop.v1.StatusChange
+	7,  // 6: coffeeshop.v1.Order.coffee:type_name -> coffeeshop.v1.Coffee
+	1,  // 7: coffeeshop.v1.OrderEvent.kind:type_name -> coffeeshop.v1.OrderEvent.Kind
+	0,  // 8: coffeeshop.v1.OrderEvent.status:type_name -> coffeeshop.v1.OrderStatus
+	14, // 9: coffeeshop.v1.OrderEvent.at:type_name -> google.protobuf.Timestamp
+	8,  // 10: coffeeshop.v1.OrderEvent.order:type_name -> coffeeshop.v1.Order
+	2,  // 11: coffeeshop.v1.CoffeeShop.GetMenu:input_type -> coffeeshop.v1.GetMenuRequest
+	5,  // 12: coffeeshop.v1.CoffeeShop.PlaceOrder:input_type -> coffeeshop.v1.PlaceOrderRequest
+	9,  // 13: coffeeshop.v1.CoffeeShop.WatchOrder:input_type -> coffeeshop.v1.WatchOrderRequest
+	11, // 14: coffeeshop.v1.CoffeeShop.CancelOrder:input_type -> coffeeshop.v1.CancelOrderRequest
+	12, // 15: coffeeshop.v1.CoffeeShop.GetStats:input_type -> coffeeshop.v1.GetStatsRequest
+	4,  // 16: coffeeshop.v1.CoffeeShop.GetMenu:output_type -> coffeeshop.v1.Menu
+	8,  // 17: coffeeshop.v1.CoffeeShop.PlaceOrder:output_type -> coffeeshop.v1.Order
+	10, // 18: coffeeshop.v1.CoffeeShop.WatchOrder:output_type -> coffeeshop.v1.OrderEvent
+	8,  // 19: coffeeshop.v1.CoffeeShop.CancelOrder:output_type -> coffeeshop.v1.Order
+	13, // 20: coffeeshop.v1.CoffeeShop.GetStats:output_type -> coffeeshop.v1.Stats
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_coffeeshop_proto_init() }
+func file_coffeeshop_proto_init() {
+	if File_coffeeshop_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_coffeeshop_proto_rawDesc), len(file_coffeeshop_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_coffeeshop_proto_goTypes,
+		DependencyIndexes: file_coffeeshop_proto_depIdxs,
+		EnumInfos:         file_coffeeshop_proto_enumTypes,
+		MessageInfos:      file_coffeeshop_proto_msgTypes,
+	}.Build()
+	File_coffeeshop_proto = out.File
+	file_coffeeshop_proto_goTypes = nil
+	file_coffeeshop_proto_depIdxs = nil
+}
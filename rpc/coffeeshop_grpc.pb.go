@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: coffeeshop.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CoffeeShop_GetMenu_FullMethodName     = "/coffeeshop.v1.CoffeeShop/GetMenu"
+	CoffeeShop_PlaceOrder_FullMethodName  = "/coffeeshop.v1.CoffeeShop/PlaceOrder"
+	CoffeeShop_WatchOrder_FullMethodName  = "/coffeeshop.v1.CoffeeShop/WatchOrder"
+	CoffeeShop_CancelOrder_FullMethodName = "/coffeeshop.v1.CoffeeShop/CancelOrder"
+	CoffeeShop_GetStats_FullMethodName    = "/coffeeshop.v1.CoffeeShop/GetStats"
+)
+
+// CoffeeShopClient is the client API for CoffeeShop service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CoffeeShop is the ordering side of a running coffee shop, the
+// kiosks to order at and the counter to watch for the coffee.
+// Times in the stats are in simulated seconds.
+type CoffeeShopClient interface {
+	// GetMenu is what can be ordered
+	GetMenu(ctx context.Context, in *GetMenuRequest, opts ...grpc.CallOption) (*Menu, error)
+	// PlaceOrder waits for a kiosk and orders
+	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// WatchOrder streams the order's progress starting with its status,
+	// the stream ends when the order is complete or cancelled
+	WatchOrder(ctx context.Context, in *WatchOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error)
+	// CancelOrder cancels an order that isn't made yet
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// GetStats is the shop stats so far
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+}
+
+type coffeeShopClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCoffeeShopClient(cc grpc.ClientConnInterface) CoffeeShopClient {
+	return &coffeeShopClient{cc}
+}
+
+func (c *coffeeShopClient) GetMenu(ctx context.Context, in *GetMenuRequest, opts ...grpc.CallOption) (*Menu, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Menu)
+	err := c.cc.Invoke(ctx, CoffeeShop_GetMenu_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coffeeShopClient) PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, CoffeeShop_PlaceOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coffeeShopClient) WatchOrder(ctx context.Context, in *WatchOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoffeeShop_ServiceDesc.Streams[0], CoffeeShop_WatchOrder_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrderRequest, OrderEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoffeeShop_WatchOrderClient = grpc.ServerStreamingClient[OrderEvent]
+
+func (c *coffeeShopClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, CoffeeShop_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coffeeShopClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, CoffeeShop_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoffeeShopServer is the server API for CoffeeShop service.
+// All implementations must embed UnimplementedCoffeeShopServer
+// for forward compatibility.
+//
+// CoffeeShop is the ordering side of a running coffee shop, the
+// kiosks to order at and the counter to watch for the coffee.
+// Times in the stats are in simulated seconds.
+type CoffeeShopServer interface {
+	// GetMenu is what can be ordered
+	GetMenu(context.Context, *GetMenuRequest) (*Menu, error)
+	// PlaceOrder waits for a kiosk and orders
+	PlaceOrder(context.Context, *PlaceOrderRequest) (*Order, error)
+	// WatchOrder streams the order's progress starting with its status,
+	// the stream ends when the order is complete or cancelled
+	WatchOrder(*WatchOrderRequest, grpc.ServerStreamingServer[OrderEvent]) error
+	// CancelOrder cancels an order that isn't made yet
+	CancelOrder(context.Context, *CancelOrderRequest) (*Order, error)
+	// GetStats is the shop stats so far
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	mustEmbedUnimplementedCoffeeShopServer()
+}
+
+// UnimplementedCoffeeShopServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCoffeeShopServer struct{}
+
+func (UnimplementedCoffeeShopServer) GetMenu(context.Context, *GetMenuRequest) (*Menu, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMenu not implemented")
+}
+func (UnimplementedCoffeeShopServer) PlaceOrder(context.Context, *PlaceOrderRequest) (*Order, error) {
+	return nil, status.Error(codes.Unimplemented, "method PlaceOrder not implemented")
+}
+func (UnimplementedCoffeeShopServer) WatchOrder(*WatchOrderRequest, grpc.ServerStreamingServer[OrderEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchOrder not implemented")
+}
+func (UnimplementedCoffeeShopServer) CancelOrder(context.Context, *CancelOrderRequest) (*Order, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedCoffeeShopServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedCoffeeShopServer) mustEmbedUnimplementedCoffeeShopServer() {}
+func (UnimplementedCoffeeShopServer) testEmbeddedByValue()                    {}
+
+// UnsafeCoffeeShopServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoffeeShopServer will
+// result in compilation errors.
+type UnsafeCoffeeShopServer interface {
+	mustEmbedUnimplementedCoffeeShopServer()
+}
+
+func RegisterCoffeeShopServer(s grpc.ServiceRegistrar, srv CoffeeShopServer) {
+	// If the following call panics, it indicates UnimplementedCoffeeShopServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CoffeeShop_ServiceDesc, srv)
+}
+
+func _CoffeeShop_GetMenu_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMenuRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoffeeShopServer).GetMenu(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoffeeShop_GetMenu_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoffeeShopServer).GetMenu(ctx, req.(*GetMenuRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoffeeShop_PlaceOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoffeeShopServer).PlaceOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoffeeShop_PlaceOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoffeeShopServer).PlaceOrder(ctx, req.(*PlaceOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoffeeShop_WatchOrder_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoffeeShopServer).WatchOrder(m, &grpc.GenericServerStream[WatchOrderRequest, OrderEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoffeeShop_WatchOrderServer = grpc.ServerStreamingServer[OrderEvent]
+
+func _CoffeeShop_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoffeeShopServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoffeeShop_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoffeeShopServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoffeeShop_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoffeeShopServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoffeeShop_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoffeeShopServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CoffeeShop_ServiceDesc is the grpc.ServiceDesc for CoffeeShop service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CoffeeShop_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "coffeeshop.v1.CoffeeShop",
+	HandlerType: (*CoffeeShopServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMenu",
+			Handler:    _CoffeeShop_GetMenu_Handler,
+		},
+		{
+			MethodName: "PlaceOrder",
+			Handler:    _CoffeeShop_PlaceOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _CoffeeShop_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _CoffeeShop_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrder",
+			Handler:       _CoffeeShop_WatchOrder_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "coffeeshop.proto",
+}
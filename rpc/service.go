@@ -0,0 +1,221 @@
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative coffeeshop.proto
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service is the gRPC ordering API for a running coffee shop,
+// register it with RegisterCoffeeShopServer.  It doesn't close
+// the shop, whoever runs the shop does.
+type Service struct {
+	UnimplementedCoffeeShopServer
+
+	shop models.CoffeeShop
+	menu models.Menu
+}
+
+func New(shop models.CoffeeShop, menu models.Menu) *Service {
+	return &Service{
+		shop: shop,
+		menu: menu,
+	}
+}
+
+func (s *Service) GetMenu(ctx context.Context, request *GetMenuRequest) (*Menu, error) {
+	result := &Menu{}
+	for _, item := range s.menu {
+		result.Items = append(result.Items, newMenuItem(item))
+	}
+
+	return result, nil
+}
+
+func (s *Service) PlaceOrder(ctx context.Context, request *PlaceOrderRequest) (*Order, error) {
+	if request.Customer == "" {
+		return nil, status.Error(codes.InvalidArgument, "the order needs a customer")
+	}
+	item, found := s.menu.Item(request.Item)
+	if !found {
+		return nil, status.Errorf(codes.InvalidArgument, "%q isn't on the menu", request.Item)
+	}
+
+	var order *models.Order
+	if kiosk := s.shop.WaitForOrderingKiosk(); kiosk != nil {
+		order = kiosk.CreateOrder(request.Customer, item)
+		s.shop.LeaveOrderingKiosk(kiosk)
+	}
+	if order == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the shop is closed")
+	}
+
+	return newOrder(order), nil
+}
+
+// WatchOrder sends the order's status then its events till it's
+// complete or cancelled, the last event has the whole order
+func (s *Service) WatchOrder(request *WatchOrderRequest, stream grpc.ServerStreamingServer[OrderEvent]) error {
+	order, err := s.order(request.Id)
+	if err != nil {
+		return err
+	}
+
+	// subscribe before looking at the status so no change is missed
+	events, stop := s.shop.Subscribe(order.ID)
+	defer stop()
+
+	current := models.ShopEvent{
+		Kind:     models.StatusEvent,
+		OrderID:  order.ID,
+		Customer: order.Customer,
+		Status:   order.GetStatus(),
+		At:       time.Now(),
+	}
+	if err := stream.Send(newOrderEvent(current, order)); err != nil || current.Finished() {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, open := <-events:
+			if !open {
+				return nil
+			}
+			sent := newOrderEvent(event, order)
+			if sent == nil {
+				continue
+			}
+			if err := stream.Send(sent); err != nil || event.Finished() {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Service) CancelOrder(ctx context.Context, request *CancelOrderRequest) (*Order, error) {
+	order, err := s.order(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Cancel() {
+		return nil, status.Errorf(codes.FailedPrecondition, "order %d is already %s", order.ID, order.GetStatus())
+	}
+
+	return newOrder(order), nil
+}
+
+func (s *Service) GetStats(ctx context.Context, request *GetStatsRequest) (*Stats, error) {
+	return newStats(s.shop.Stats()), nil
+}
+
+func (s *Service) order(id int64) (*models.Order, error) {
+	order := s.shop.Order(id)
+	if order == nil {
+		return nil, status.Errorf(codes.NotFound, "no order %d", id)
+	}
+
+	return order, nil
+}
+
+func newMenuItem(item models.MenuItem) *MenuItem {
+	result := &MenuItem{
+		Name:    item.Name,
+		SizeMl:  float64(item.Size),
+		Ratio:   float64(item.CoffeeRatio),
+		Variety: item.Variety.String(),
+	}
+	for _, method := range item.Methods {
+		result.Methods = append(result.Methods, method.String())
+	}
+
+	return result
+}
+
+var orderStatuses = map[models.OrderStatus]OrderStatus{
+	models.Ordered:      OrderStatus_ORDER_STATUS_ORDERED,
+	models.ReadyToGrind: OrderStatus_ORDER_STATUS_READY_TO_GRIND,
+	models.Grinding:     OrderStatus_ORDER_STATUS_GRINDING,
+	models.ReadyToBrew:  OrderStatus_ORDER_STATUS_READY_TO_BREW,
+	models.Brewing:      OrderStatus_ORDER_STATUS_BREWING,
+	models.Complete:     OrderStatus_ORDER_STATUS_COMPLETE,
+	models.Cancelled:    OrderStatus_ORDER_STATUS_CANCELLED,
+}
+
+func newOrder(order *models.Order) *Order {
+	result := &Order{
+		Id:       order.ID,
+		Customer: order.Customer,
+		Item:     order.Item.Name,
+		Status:   orderStatuses[order.GetStatus()],
+	}
+	for _, change := range order.Timeline() {
+		result.Timeline = append(result.Timeline, &StatusChange{
+			Status: orderStatuses[change.Status],
+			At:     timestamppb.New(change.At),
+		})
+	}
+	if coffee := order.Coffee(); coffee != nil {
+		result.Coffee = &Coffee{
+			VolumeMl: float64(coffee.Volume()),
+			Method:   coffee.Method().String(),
+			Body:     coffee.Body().String(),
+			Variety:  coffee.Variety().String(),
+			BrewedAt: timestamppb.New(coffee.BrewedAt()),
+		}
+	}
+
+	return result
+}
+
+// newOrderEvent converts an order's event, the last one gets the
+// whole order.  It's nil for an event an order doesn't have.
+func newOrderEvent(event models.ShopEvent, order *models.Order) *OrderEvent {
+	result := &OrderEvent{
+		OrderId: event.OrderID,
+		Status:  orderStatuses[event.Status],
+		Step:    event.Step,
+		Barista: event.Barista,
+		At:      timestamppb.New(event.At),
+	}
+	switch event.Kind {
+	case models.StatusEvent:
+		result.Kind = OrderEvent_KIND_STATUS
+	case models.StepEvent:
+		result.Kind = OrderEvent_KIND_STEP
+	default:
+		return nil
+	}
+	if event.Finished() {
+		result.Order = newOrder(order)
+	}
+
+	return result
+}
+
+// newStats converts the stats, times are in simulated seconds
+func newStats(stats models.Stats) *Stats {
+	return &Stats{
+		OrdersServed:       int64(stats.OrdersServed),
+		Cancelled:          int64(stats.Cancelled),
+		AverageWaitSeconds: models.SimSeconds(stats.AverageWait()),
+		MaxWaitSeconds:     models.SimSeconds(stats.MaxWait),
+		Remakes:            int64(stats.Remakes),
+		AverageQuality:     stats.AverageQuality(),
+		BatchesBrewed:      int64(stats.BatchesBrewed),
+		CarafeCupsPoured:   int64(stats.CarafeCupsPoured),
+		CarafeCupsWasted:   int64(stats.CarafeCupsWasted),
+		StaffedHours:       stats.StaffedHours(),
+		OrdersPerLaborHour: stats.OrdersPerLaborHour(),
+		OpenSeconds:        models.SimSeconds(stats.OpenTime),
+	}
+}
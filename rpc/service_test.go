@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Test Helpers
+func getTestMenu() models.Menu {
+	return models.Menu{
+		models.MenuItem{
+			Name:        "Regular",
+			Size:        240,
+			CoffeeRatio: 16,
+			Variety:     models.BeanVariety{Origin: "Test", Roast: models.MediumRoast},
+			Methods:     []models.BrewMethod{models.Drip},
+		},
+	}
+}
+
+// newTestClient serves a shop with a drip brewer making the given
+// ml per second over an in-memory connection
+func newTestClient(t *testing.T, waterPerSecond float64) (CoffeeShopClient, models.CoffeeShop) {
+	menu := getTestMenu()
+	shop := models.NewCoffeeShop(menu, 1, 1, 5,
+		models.NewGrinderPool(models.NewGrinder(100)),
+		models.NewBrewerPool(models.NewBrewer(100,
+			models.WithBrewModel(models.NewRateDuration(waterPerSecond)))))
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterCoffeeShopServer(server, New(shop, menu))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return NewCoffeeShopClient(conn), shop
+}
+
+func TestGetMenu(t *testing.T) {
+	client, _ := newTestClient(t, 1000)
+
+	menu, err := client.GetMenu(context.Background(), &GetMenuRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, menu.Items, 1)
+	assert.Equal(t, "Regular", menu.Items[0].Name)
+	assert.Equal(t, 240.0, menu.Items[0].SizeMl)
+	assert.Equal(t, "Test Medium", menu.Items[0].Variety)
+	assert.Equal(t, []string{"Drip"}, menu.Items[0].Methods)
+}
+
+func TestPlaceAndWatchOrder(t *testing.T) {
+	client, shop := newTestClient(t, 100)
+	ctx := context.Background()
+
+	placed, err := client.PlaceOrder(ctx, &PlaceOrderRequest{Customer: "test", Item: "Regular"})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", placed.Customer)
+
+	stream, err := client.WatchOrder(ctx, &WatchOrderRequest{Id: placed.Id})
+	assert.NoError(t, err)
+	events := []*OrderEvent{}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		events = append(events, event)
+	}
+
+	// the stream ends with the order and its coffee
+	assert.Equal(t, OrderEvent_KIND_STATUS, events[0].Kind)
+	last := events[len(events)-1]
+	assert.Equal(t, OrderStatus_ORDER_STATUS_COMPLETE, last.Status)
+	assert.Equal(t, placed.Id, last.Order.Id)
+	assert.Equal(t, 240.0, last.Order.Coffee.VolumeMl)
+	assert.Equal(t, OrderStatus_ORDER_STATUS_ORDERED, last.Order.Timeline[0].Status)
+	for _, event := range events {
+		assert.Equal(t, placed.Id, event.OrderId)
+	}
+
+	stats, err := client.GetStats(ctx, &GetStatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.OrdersServed)
+
+	// the shop's pool events aren't the order's
+	assert.Nil(t, newOrderEvent(models.ShopEvent{Kind: models.PoolEvent}, nil))
+
+	// closed for orders
+	shop.Close()
+	_, err = client.PlaceOrder(ctx, &PlaceOrderRequest{Customer: "late", Item: "Regular"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestCancelOrder(t *testing.T) {
+	// slow enough the order is still brewing
+	client, shop := newTestClient(t, 1)
+	ctx := context.Background()
+
+	placed, err := client.PlaceOrder(ctx, &PlaceOrderRequest{Customer: "test", Item: "Regular"})
+	assert.NoError(t, err)
+
+	order, err := client.CancelOrder(ctx, &CancelOrderRequest{Id: placed.Id})
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatus_ORDER_STATUS_CANCELLED, order.Status)
+	assert.Nil(t, order.Coffee)
+
+	// it can only be cancelled once
+	_, err = client.CancelOrder(ctx, &CancelOrderRequest{Id: placed.Id})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// watching a finished order just sends its status
+	stream, err := client.WatchOrder(ctx, &WatchOrderRequest{Id: placed.Id})
+	assert.NoError(t, err)
+	event, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatus_ORDER_STATUS_CANCELLED, event.Order.Status)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	shop.Close()
+	assert.Equal(t, 1, shop.Stats().Cancelled)
+}
+
+func TestBadRequests(t *testing.T) {
+	client, _ := newTestClient(t, 1000)
+	ctx := context.Background()
+
+	_, err := client.PlaceOrder(ctx, &PlaceOrderRequest{Customer: "test", Item: "Latte"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "Latte")
+
+	_, err = client.PlaceOrder(ctx, &PlaceOrderRequest{Item: "Regular"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CancelOrder(ctx, &CancelOrderRequest{Id: 12345678})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.WatchOrder(ctx, &WatchOrderRequest{Id: 12345678})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"time"
+)
+
+// the request and response bodies of the API
+
+type Error struct {
+	Error string `json:"error"`
+}
+
+type PlaceOrder struct {
+	Customer string `json:"customer"`
+	Item     string `json:"item"`
+}
+
+type MenuItem struct {
+	Name    string   `json:"name"`
+	SizeML  float64  `json:"size_ml"`
+	Ratio   float64  `json:"ratio"`
+	Variety string   `json:"variety"`
+	Methods []string `json:"methods,omitempty"`
+}
+
+func newMenuItem(item models.MenuItem) MenuItem {
+	result := MenuItem{
+		Name:    item.Name,
+		SizeML:  float64(item.Size),
+		Ratio:   float64(item.CoffeeRatio),
+		Variety: item.Variety.String(),
+	}
+	for _, method := range item.Methods {
+		result.Methods = append(result.Methods, method.String())
+	}
+
+	return result
+}
+
+type StatusChange struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+type Coffee struct {
+	VolumeML float64   `json:"volume_ml"`
+	Method   string    `json:"method"`
+	Body     string    `json:"body"`
+	Variety  string    `json:"variety"`
+	BrewedAt time.Time `json:"brewed_at"`
+}
+
+type Order struct {
+	ID       int64          `json:"id"`
+	Customer string         `json:"customer"`
+	Item     string         `json:"item"`
+	Status   string         `json:"status"`
+	Timeline []StatusChange `json:"timeline"`
+	// the coffee once it's made
+	Coffee *Coffee `json:"coffee,omitempty"`
+}
+
+func newOrder(order *models.Order) Order {
+	result := Order{
+		ID:       order.ID,
+		Customer: order.Customer,
+		Item:     order.Item.Name,
+		Status:   order.GetStatus().String(),
+	}
+	for _, change := range order.Timeline() {
+		result.Timeline = append(result.Timeline, StatusChange{Status: change.Status.String(), At: change.At})
+	}
+	if coffee := order.Coffee(); coffee != nil {
+		result.Coffee = &Coffee{
+			VolumeML: float64(coffee.Volume()),
+			Method:   coffee.Method().String(),
+			Body:     coffee.Body().String(),
+			Variety:  coffee.Variety().String(),
+			BrewedAt: coffee.BrewedAt(),
+		}
+	}
+
+	return result
+}
+
+// Stats times are in simulated seconds
+type Stats struct {
+	OrdersServed       int     `json:"orders_served"`
+	Cancelled          int     `json:"cancelled"`
+	AverageWaitSeconds float64 `json:"average_wait_seconds"`
+	MaxWaitSeconds     float64 `json:"max_wait_seconds"`
+	Remakes            int     `json:"remakes"`
+	AverageQuality     float64 `json:"average_quality"`
+	BatchesBrewed      int     `json:"batches_brewed"`
+	CarafeCupsPoured   int     `json:"carafe_cups_poured"`
+	CarafeCupsWasted   int     `json:"carafe_cups_wasted"`
+	StaffedHours       float64 `json:"staffed_hours"`
+	OrdersPerLaborHour float64 `json:"orders_per_labor_hour"`
+	OpenSeconds        float64 `json:"open_seconds"`
+}
+
+func newStats(stats models.Stats) Stats {
+	return Stats{
+		OrdersServed:       stats.OrdersServed,
+		Cancelled:          stats.Cancelled,
+		AverageWaitSeconds: models.SimSeconds(stats.AverageWait()),
+		MaxWaitSeconds:     models.SimSeconds(stats.MaxWait),
+		Remakes:            stats.Remakes,
+		AverageQuality:     stats.AverageQuality(),
+		BatchesBrewed:      stats.BatchesBrewed,
+		CarafeCupsPoured:   stats.CarafeCupsPoured,
+		CarafeCupsWasted:   stats.CarafeCupsWasted,
+		StaffedHours:       stats.StaffedHours(),
+		OrdersPerLaborHour: stats.OrdersPerLaborHour(),
+		OpenSeconds:        models.SimSeconds(stats.OpenTime),
+	}
+}
+
+type Event struct {
+	Kind     string    `json:"kind"`
+	OrderID  int64     `json:"order_id,omitempty"`
+	Customer string    `json:"customer,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Step     string    `json:"step,omitempty"`
+	Barista  string    `json:"barista,omitempty"`
+	At       time.Time `json:"at"`
+	// what's free in the pools for pool events
+	GrindersFree    *int `json:"grinders_free,omitempty"`
+	BrewerSlotsFree *int `json:"brewer_slots_free,omitempty"`
+}
+
+func newEvent(event models.ShopEvent) Event {
+	result := Event{
+		Kind:     string(event.Kind),
+		OrderID:  event.OrderID,
+		Customer: event.Customer,
+		Step:     event.Step,
+		Barista:  event.Barista,
+		At:       event.At,
+	}
+	if event.Kind == models.PoolEvent {
+		result.GrindersFree = &event.GrindersFree
+		result.BrewerSlotsFree = &event.BrewerSlotsFree
+	} else {
+		result.Status = event.Status.String()
+	}
+
+	return result
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a JSON HTTP API for a running coffee shop
+//
+//	GET    /menu         the menu
+//	POST   /orders       place an order at a kiosk, {"customer": "", "item": ""}
+//	GET    /orders/{id}  an order with its status and timeline
+//	DELETE /orders/{id}  cancel an order
+//	GET    /orders/{id}/events  the order's events as they happen
+//	GET    /events       every event in the shop as it happens
+//	GET    /stats        the shop stats so far
+//	POST   /close        close the shop once the orders are done
+//	GET    /metrics      the shop metrics in the Prometheus text format
+//
+// Events are streamed as Server-Sent Events, named for their kind.
+type Server struct {
+	shop models.CoffeeShop
+	menu models.Menu
+	mux  *http.ServeMux
+
+	lock   *sync.Mutex
+	closed bool
+}
+
+func New(shop models.CoffeeShop, menu models.Menu) *Server {
+	result := &Server{
+		shop: shop,
+		menu: menu,
+		mux:  http.NewServeMux(),
+		lock: &sync.Mutex{},
+	}
+
+	result.mux.HandleFunc("/menu", result.handleMenu)
+	result.mux.HandleFunc("/orders", result.handleOrders)
+	result.mux.HandleFunc("/orders/", result.handleOrder)
+	result.mux.HandleFunc("/events", result.handleEvents)
+	result.mux.HandleFunc("/stats", result.handleStats)
+	result.mux.HandleFunc("/close", result.handleClose)
+	result.mux.Handle("/metrics", MetricsHandler(shop))
+
+	return result
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleMenu(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	result := make([]MenuItem, 0, len(s.menu))
+	for _, item := range s.menu {
+		result = append(result, newMenuItem(item))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	request := PlaceOrder{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "bad order: "+err.Error())
+		return
+	}
+	if request.Customer == "" {
+		writeError(w, http.StatusBadRequest, "the order needs a customer")
+		return
+	}
+	item, found := s.menu.Item(request.Item)
+	if !found {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("%q isn't on the menu", request.Item))
+		return
+	}
+
+	order := s.placeOrder(request.Customer, item)
+	if order == nil {
+		writeError(w, http.StatusConflict, "the shop is closed")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newOrder(order))
+}
+
+// placeOrder waits for a kiosk and orders, nil if the shop is closed
+func (s *Server) placeOrder(customer string, item models.MenuItem) *models.Order {
+	kiosk := s.shop.WaitForOrderingKiosk()
+	if kiosk == nil {
+		return nil
+	}
+	order := kiosk.CreateOrder(customer, item)
+	s.shop.LeaveOrderingKiosk(kiosk)
+
+	return order
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/orders/")
+	path, streaming := trimSuffix(path, "/events")
+	methods := []string{http.MethodGet, http.MethodDelete}
+	if streaming {
+		methods = []string{http.MethodGet}
+	}
+	if !allowMethod(w, r, methods...) {
+		return
+	}
+
+	id, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "no such order")
+		return
+	}
+	order := s.shop.Order(id)
+	if order == nil {
+		writeError(w, http.StatusNotFound, "no such order")
+		return
+	}
+
+	if streaming {
+		s.streamEvents(w, r, order)
+		return
+	}
+	if r.Method == http.MethodDelete && !order.Cancel() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("order %d is already %s", id, order.GetStatus()))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newOrder(order))
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	s.streamEvents(w, r, nil)
+}
+
+// streamEvents sends the events of the order, or the whole shop for a nil
+// order, till the client goes away.  An order's stream starts with its
+// status and ends when it's complete or cancelled.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, order *models.Order) {
+	flusher, canFlush := w.(http.Flusher)
+	if !canFlush {
+		writeError(w, http.StatusInternalServerError, "streaming isn't supported")
+		return
+	}
+
+	// subscribe before looking at the status so no change is missed
+	orderID := int64(0)
+	if order != nil {
+		orderID = order.ID
+	}
+	events, stop := s.shop.Subscribe(orderID)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if order != nil {
+		current := models.ShopEvent{
+			Kind:     models.StatusEvent,
+			OrderID:  order.ID,
+			Customer: order.Customer,
+			Status:   order.GetStatus(),
+			At:       time.Now(),
+		}
+		writeEvent(w, current)
+		if current.Finished() {
+			flusher.Flush()
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+			if order != nil && event.Finished() {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newStats(s.shop.Stats()))
+}
+
+func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	s.lock.Lock()
+	alreadyClosed := s.closed
+	s.closed = true
+	s.lock.Unlock()
+	if alreadyClosed {
+		writeError(w, http.StatusConflict, "the shop is already closed")
+		return
+	}
+
+	// closing waits on the baristas to finish up
+	s.shop.Close()
+	writeJSON(w, http.StatusOK, newStats(s.shop.Stats()))
+}
+
+// MetricsHandler serves the shop metrics for Prometheus to scrape,
+// it's for serving them on their own while a simulation runs
+func MetricsHandler(shop models.CoffeeShop) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := shop.WriteMetrics(w); err != nil {
+			fmt.Println("Error writing metrics", err)
+		}
+	})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, r.Method+" isn't allowed")
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println("Error writing response", err)
+	}
+}
+
+// writeEvent writes a Server-Sent Event named for its kind
+func writeEvent(w http.ResponseWriter, event models.ShopEvent) {
+	data, err := json.Marshal(newEvent(event))
+	if err != nil {
+		fmt.Println("Error writing event", err)
+		return
+	}
+
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Kind, data)
+}
+
+func trimSuffix(s string, suffix string) (string, bool) {
+	if strings.HasSuffix(s, suffix) {
+		return strings.TrimSuffix(s, suffix), true
+	}
+
+	return s, false
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Error{Error: message})
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Helpers
+func getTestMenu() models.Menu {
+	return models.Menu{
+		models.MenuItem{
+			Name:        "Regular",
+			Size:        240,
+			CoffeeRatio: 16,
+			Variety:     models.BeanVariety{Origin: "Test", Roast: models.MediumRoast},
+			Methods:     []models.BrewMethod{models.Drip},
+		},
+	}
+}
+
+// newTestServer runs a shop with a drip brewer making the given ml per second
+func newTestServer(waterPerSecond float64) *httptest.Server {
+	menu := getTestMenu()
+	shop := models.NewCoffeeShop(menu, 1, 1, 5,
+		models.NewGrinderPool(models.NewGrinder(100)),
+		models.NewBrewerPool(models.NewBrewer(100,
+			models.WithBrewModel(models.NewRateDuration(waterPerSecond)))))
+
+	return httptest.NewServer(New(shop, menu))
+}
+
+func send(t *testing.T, method string, url string, body interface{}, result interface{}) int {
+	payload := &bytes.Buffer{}
+	if body != nil {
+		assert.NoError(t, json.NewEncoder(payload).Encode(body))
+	}
+	request, err := http.NewRequest(method, url, payload)
+	assert.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	if result != nil {
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(result))
+	}
+
+	return response.StatusCode
+}
+
+func placeOrder(t *testing.T, ts *httptest.Server) Order {
+	order := Order{}
+	status := send(t, http.MethodPost, ts.URL+"/orders", PlaceOrder{Customer: "test", Item: "Regular"}, &order)
+	assert.Equal(t, http.StatusCreated, status)
+	return order
+}
+
+func TestMenu(t *testing.T) {
+	ts := newTestServer(1000)
+	defer ts.Close()
+
+	menu := []MenuItem{}
+	assert.Equal(t, http.StatusOK, send(t, http.MethodGet, ts.URL+"/menu", nil, &menu))
+	assert.Equal(t, []MenuItem{{
+		Name:    "Regular",
+		SizeML:  240,
+		Ratio:   16,
+		Variety: "Test Medium",
+		Methods: []string{"Drip"},
+	}}, menu)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, send(t, http.MethodPost, ts.URL+"/menu", nil, nil))
+}
+
+func TestPlaceAndGetOrder(t *testing.T) {
+	ts := newTestServer(1000)
+	defer ts.Close()
+
+	placed := placeOrder(t, ts)
+	assert.Equal(t, "test", placed.Customer)
+	assert.Equal(t, "Regular", placed.Item)
+
+	// poll till it's made
+	order := Order{}
+	url := ts.URL + "/orders/" + strconv.FormatInt(placed.ID, 10)
+	assert.Eventually(t, func() bool {
+		assert.Equal(t, http.StatusOK, send(t, http.MethodGet, url, nil, &order))
+		return order.Status == models.Complete.String()
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, placed.ID, order.ID)
+	assert.Equal(t, models.Ordered.String(), order.Timeline[0].Status)
+	assert.Equal(t, models.Complete.String(), order.Timeline[len(order.Timeline)-1].Status)
+	assert.NotNil(t, order.Coffee)
+	assert.Equal(t, 240.0, order.Coffee.VolumeML)
+	assert.Equal(t, "Drip", order.Coffee.Method)
+
+	stats := Stats{}
+	assert.Equal(t, http.StatusOK, send(t, http.MethodGet, ts.URL+"/stats", nil, &stats))
+	assert.Equal(t, 1, stats.OrdersServed)
+}
+
+func TestBadOrders(t *testing.T) {
+	ts := newTestServer(1000)
+	defer ts.Close()
+
+	apiError := Error{}
+	status := send(t, http.MethodPost, ts.URL+"/orders", PlaceOrder{Customer: "test", Item: "Latte"}, &apiError)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, apiError.Error, "Latte")
+
+	status = send(t, http.MethodPost, ts.URL+"/orders", PlaceOrder{Item: "Regular"}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	assert.Equal(t, http.StatusNotFound, send(t, http.MethodGet, ts.URL+"/orders/12345678", nil, nil))
+	assert.Equal(t, http.StatusNotFound, send(t, http.MethodGet, ts.URL+"/orders/nope", nil, nil))
+}
+
+func TestCancelOrder(t *testing.T) {
+	// slow enough the order is still brewing
+	ts := newTestServer(1)
+	defer ts.Close()
+
+	placed := placeOrder(t, ts)
+	url := ts.URL + "/orders/" + strconv.FormatInt(placed.ID, 10)
+
+	order := Order{}
+	assert.Equal(t, http.StatusOK, send(t, http.MethodDelete, url, nil, &order))
+	assert.Equal(t, models.Cancelled.String(), order.Status)
+	assert.Nil(t, order.Coffee)
+
+	// it can only be cancelled once
+	assert.Equal(t, http.StatusConflict, send(t, http.MethodDelete, url, nil, nil))
+
+	// closing waits for the barista to drop it
+	stats := Stats{}
+	assert.Equal(t, http.StatusOK, send(t, http.MethodPost, ts.URL+"/close", nil, &stats))
+	assert.Equal(t, 1, stats.Cancelled)
+	assert.Equal(t, 0, stats.OrdersServed)
+}
+
+func TestCloseShop(t *testing.T) {
+	ts := newTestServer(1000)
+	defer ts.Close()
+
+	placeOrder(t, ts)
+
+	stats := Stats{}
+	assert.Equal(t, http.StatusOK, send(t, http.MethodPost, ts.URL+"/close", nil, &stats))
+	assert.Equal(t, 1, stats.OrdersServed)
+
+	// closed for orders and closing
+	status := send(t, http.MethodPost, ts.URL+"/orders", PlaceOrder{Customer: "late", Item: "Regular"}, nil)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, http.StatusConflict, send(t, http.MethodPost, ts.URL+"/close", nil, nil))
+}
+
+// readEvents reads Server-Sent Events till the stream ends or
+// the count of events is read
+func readEvents(t *testing.T, body io.Reader, count int) []Event {
+	result := []Event{}
+	scanner := bufio.NewScanner(body)
+	name := ""
+	for len(result) < count && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event := Event{}
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			assert.Equal(t, name, event.Kind)
+			result = append(result, event)
+		}
+	}
+
+	return result
+}
+
+func TestOrderEvents(t *testing.T) {
+	ts := newTestServer(100)
+	defer ts.Close()
+
+	placed := placeOrder(t, ts)
+	response, err := http.Get(ts.URL + "/orders/" + strconv.FormatInt(placed.ID, 10) + "/events")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	// the stream ends when the order is complete
+	events := readEvents(t, response.Body, 100)
+	last := events[len(events)-1]
+	assert.Equal(t, "status", last.Kind)
+	assert.Equal(t, models.Complete.String(), last.Status)
+	for _, event := range events {
+		assert.Equal(t, placed.ID, event.OrderID)
+	}
+
+	// a finished order just sends its status
+	response, err = http.Get(ts.URL + "/orders/" + strconv.FormatInt(placed.ID, 10) + "/events")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	events = readEvents(t, response.Body, 100)
+	assert.Len(t, events, 1)
+	assert.Equal(t, models.Complete.String(), events[0].Status)
+}
+
+func TestShopEvents(t *testing.T) {
+	ts := newTestServer(100)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	assert.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	placeOrder(t, ts)
+
+	// every event for the order and the pools, the first
+	// is the order placed
+	events := readEvents(t, response.Body, 14)
+	assert.Equal(t, models.Ordered.String(), events[0].Status)
+	kinds := map[string]int{}
+	for _, event := range events {
+		kinds[event.Kind] += 1
+		if event.Kind == "pool" {
+			assert.NotNil(t, event.GrindersFree)
+			assert.NotNil(t, event.BrewerSlotsFree)
+		}
+	}
+	assert.Equal(t, map[string]int{"status": 6, "step": 4, "pool": 4}, kinds)
+}
+
+func TestMetrics(t *testing.T) {
+	ts := newTestServer(1000)
+	defer ts.Close()
+
+	placeOrder(t, ts)
+	send(t, http.MethodPost, ts.URL+"/close", nil, nil)
+
+	response, err := http.Get(ts.URL + "/metrics")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4", response.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `coffeeshop_orders{status="complete"} 1`)
+	assert.Contains(t, string(body), "# TYPE coffeeshop_equipment_busy_seconds_total counter")
+}
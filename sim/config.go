@@ -0,0 +1,89 @@
+package sim
+
+import "errors"
+
+// Config is everything a simulation's shop and customers are built from,
+// the command line flags set it
+type Config struct {
+	GrinderCount      int
+	DecafGrinderCount int
+	BrewerCount       int
+	BrewerCups        int
+	PourOverCount     int
+	FrenchPressCount  int
+	AeroPressCount    int
+	BatchBrewerCount  int
+	CarafeCups        int
+	CarafeHoldSeconds int
+	KioskCount        int
+	BaristaCount      int
+	CustomerCount     int
+	BaristaOrderCount int
+	// Seed for the random parts of the simulation, 0 picks one from the clock
+	Seed             int64
+	TimingSpread     float64
+	WarmUpSeconds    int
+	IdleSeconds      int
+	BaristaSpeed     float64
+	BaristaErrorRate float64
+	TraineeCount     int
+	// OpenHours spreads the customers through the day, 0 has
+	// them all arrive at once
+	OpenHours            float64
+	ShiftHours           float64
+	BreakMinutes         int
+	AutoscaleMax         int
+	AutoscaleQueue       int
+	AutoscaleWaitSeconds int
+	// Workload is the customers to send in, when there are some
+	// they replace the customer count's random ones
+	Workload []Arrival
+}
+
+// DefaultConfig is a small shop with one of everything
+func DefaultConfig() Config {
+	return Config{
+		GrinderCount:         1,
+		BrewerCount:          1,
+		BrewerCups:           4,
+		CarafeCups:           10,
+		CarafeHoldSeconds:    300,
+		KioskCount:           1,
+		BaristaCount:         1,
+		CustomerCount:        1,
+		BaristaOrderCount:    5,
+		BaristaSpeed:         1,
+		ShiftHours:           8,
+		BreakMinutes:         30,
+		AutoscaleQueue:       10,
+		AutoscaleWaitSeconds: 600,
+	}
+}
+
+func (c Config) validate() error {
+	// dedicated decaf grinders can't serve the rest of the menu
+	if c.GrinderCount < 1 {
+		return errors.New("grinder-count must be at least 1")
+	}
+	if c.KioskCount < 1 {
+		return errors.New("kiosk-count must be at least 1")
+	}
+	// without these an order is never finished and the run never ends
+	if c.BaristaCount < 1 {
+		return errors.New("barista-count must be at least 1")
+	}
+	if c.BaristaOrderCount < 1 {
+		return errors.New("barista-order-count must be at least 1")
+	}
+	if c.BrewerCount+c.PourOverCount+c.FrenchPressCount+c.AeroPressCount+c.BatchBrewerCount < 1 {
+		return errors.New("the shop needs at least 1 brewer of some kind")
+	}
+	if c.BrewerCount > 0 && c.BrewerCups < 1 {
+		return errors.New("brewer-cups must be at least 1")
+	}
+	if c.BatchBrewerCount > 0 && c.CarafeCups < 1 {
+		return errors.New("carafe-cups must be at least 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/synctest"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "Rewrite the golden files in testdata/golden with the runs")
+
+// goldenCase is a canonical run of the shop, its order
+// timeline and stats are checked against its golden file
+type goldenCase struct {
+	name     string
+	config   func(t *testing.T) Config
+	scenario string
+}
+
+var goldenCases = []goldenCase{
+	{
+		name: "one-barista",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.CustomerCount = 15
+			config.OpenHours = 0.1
+			config.BreakMinutes = 0
+			config.Seed = 11
+			return config
+		},
+	},
+	{
+		name: "staggered-shifts",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.GrinderCount = 2
+			config.BrewerCount = 2
+			config.BaristaCount = 3
+			config.CustomerCount = 30
+			config.OpenHours = 0.2
+			config.ShiftHours = 0.1
+			config.BreakMinutes = 0
+			config.BaristaErrorRate = 0.1
+			config.TimingSpread = 0.3
+			config.Seed = 12
+			return config
+		},
+	},
+	{
+		name: "trainee",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.DecafGrinderCount = 1
+			config.PourOverCount = 1
+			config.FrenchPressCount = 1
+			config.AeroPressCount = 1
+			config.BaristaCount = 2
+			config.TraineeCount = 1
+			config.CustomerCount = 25
+			config.OpenHours = 0.15
+			config.ShiftHours = 0.1
+			config.BreakMinutes = 0
+			config.Seed = 13
+			return config
+		},
+	},
+	{
+		name: "mixed-brewers",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.DecafGrinderCount = 1
+			config.PourOverCount = 1
+			config.FrenchPressCount = 1
+			config.AeroPressCount = 1
+			config.CustomerCount = 25
+			config.OpenHours = 0.15
+			config.BreakMinutes = 0
+			config.WarmUpSeconds = 30
+			config.IdleSeconds = 60
+			config.Seed = 13
+			return config
+		},
+	},
+	{
+		name: "batch-brewing",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.BrewerCount = 0
+			config.BatchBrewerCount = 1
+			config.CustomerCount = 20
+			config.OpenHours = 0.1
+			config.BreakMinutes = 0
+			config.Seed = 14
+			return config
+		},
+	},
+	{
+		name: "autoscaler",
+		config: func(*testing.T) Config {
+			config := DefaultConfig()
+			config.GrinderCount = 2
+			config.BrewerCount = 2
+			config.AutoscaleMax = 3
+			config.AutoscaleQueue = 3
+			config.CustomerCount = 30
+			config.OpenHours = 0.05
+			config.BreakMinutes = 0
+			config.Seed = 15
+			return config
+		},
+	},
+	{
+		name: "breakdown",
+		config: func(*testing.T) Config {
+			config := scenarioConfig()
+			config.ShiftHours = 0.08
+			return config
+		},
+		scenario: "testdata/scenarios/breakdown.scenario",
+	},
+	{
+		name: "workload",
+		config: func(t *testing.T) Config {
+			workload, err := LoadWorkload("testdata/workloads/morning.csv", Menu())
+			assert.NoError(t, err)
+			config := DefaultConfig()
+			config.BaristaCount = 2
+			config.BaristaErrorRate = 0.1
+			config.TimingSpread = 0.3
+			config.Workload = workload
+			config.Seed = 16
+			return config
+		},
+	},
+}
+
+// goldenRun runs the case with a fake clock so the times are exact, a
+// simulated second is a millisecond on the clock whatever the machine
+func goldenRun(t *testing.T, gc goldenCase) string {
+	scenario := &Scenario{}
+	if gc.scenario != "" {
+		loaded, err := LoadScenario(gc.scenario)
+		assert.NoError(t, err)
+		scenario = loaded
+	}
+
+	report := &strings.Builder{}
+	synctest.Test(t, func(t *testing.T) {
+		timeline := NewTimeline()
+		result, failures, err := RunScenario(gc.config(t), scenario, models.WithTracer(timeline))
+		assert.NoError(t, err)
+		assert.Empty(t, failures)
+
+		fmt.Fprintf(report, "# %s, go test ./sim -run TestGolden -update rewrites it\n", gc.name)
+		timeline.Write(report)
+		fmt.Fprintln(report)
+		report.WriteString(result.Stats.String())
+		for _, statistic := range append(Statistics, scenarioStatistics...) {
+			fmt.Fprintf(report, "%s %.3f\n", statistic.Name, statistic.Of(result))
+		}
+	})
+
+	return report.String()
+}
+
+// TestGolden checks each canonical run against its golden file, with
+// -update it writes the runs to the files instead.  The shop's goroutines
+// run one at a time so a run is the same every time on the same Go.
+func TestGolden(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector shuffles goroutines so the runs aren't repeatable")
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	for _, gc := range goldenCases {
+		t.Run(gc.name, func(t *testing.T) {
+			actual := goldenRun(t, gc)
+			if again := goldenRun(t, gc); again != actual {
+				t.Fatalf("the run isn't repeatable so it can't be checked:\n%s",
+					goldenDiff("first run", "second run", actual, again))
+			}
+
+			path := filepath.Join("testdata", "golden", gc.name+".golden")
+			if *updateGolden {
+				assert.NoError(t, os.WriteFile(path, []byte(actual), 0o644))
+				return
+			}
+
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v, go test ./sim -run TestGolden -update writes it", err)
+			}
+			if string(expected) != actual {
+				t.Errorf("the run doesn't match %s, check the changes and rewrite it with -update if they're right:\n%s",
+					path, goldenDiff(path, "run", string(expected), actual))
+			}
+		})
+	}
+}
+
+// goldenDiff is a unified diff of the lines with a few lines around each change
+func goldenDiff(fromName string, toName string, from string, to string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return diff
+}
@@ -0,0 +1,264 @@
+package sim
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Costs are what a barista, grinder, brewer and kiosk each cost
+type Costs struct {
+	Barista float64
+	Grinder float64
+	Brewer  float64
+	Kiosk   float64
+}
+
+// Of is the cost of the config's staff and equipment
+func (c Costs) Of(config Config) float64 {
+	return c.Barista*float64(config.BaristaCount) +
+		c.Grinder*float64(config.GrinderCount+config.DecafGrinderCount) +
+		c.Brewer*float64(brewers(config)) +
+		c.Kiosk*float64(config.KioskCount)
+}
+
+func brewers(config Config) int {
+	return config.BrewerCount + config.PourOverCount + config.FrenchPressCount +
+		config.AeroPressCount + config.BatchBrewerCount
+}
+
+// Candidate is a setup the optimizer looked at
+type Candidate struct {
+	Point
+	Cost float64
+	// Row is how it did, when it was run
+	Row Row
+	Ran bool
+	// MeetsSLA is true when its p95 wait was within the SLA
+	MeetsSLA bool
+	// Frontier is true when no other candidate run was as cheap with
+	// a shorter p95 wait
+	Frontier bool
+}
+
+// Optimizer looks for the cheapest of the points with a p95 wait within
+// the SLA, taking more staff or equipment to never make the waits longer.
+// It first runs the points with the most of everything, the points with
+// no more of anything than one that missed the SLA are skipped.  Then it
+// runs the rest a cost at a time from the cheapest up and stops at the
+// first cost with a point that meets the SLA.
+type Optimizer struct {
+	Points       []Point
+	Costs        Costs
+	SLA          time.Duration
+	Replications int
+	Parallel     int
+	// Progress is told about each run as it finishes, it can be nil
+	Progress func(done int, total int, point Point, err error)
+}
+
+// Run returns the cheapest candidate that meets the SLA, nil if none do,
+// and the candidates it looked at from the cheapest up
+func (o Optimizer) Run() (*Candidate, []Candidate, error) {
+	candidates := []Candidate{}
+	for _, point := range o.Points {
+		candidates = append(candidates, Candidate{Point: point, Cost: o.Costs.Of(point.Config)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Cost < candidates[j].Cost
+	})
+
+	// the progress counts the runs across the costs, out of
+	// the most it could take
+	runs := 0
+	most := len(candidates) * max(o.Replications, 1)
+	missed := []Config{}
+	run := func(toRun []int) error {
+		points := []Point{}
+		for _, i := range toRun {
+			points = append(points, candidates[i].Point)
+		}
+		rows, err := Sweep{
+			Points:       points,
+			Replications: o.Replications,
+			Parallel:     o.Parallel,
+			Progress: func(done int, total int, point Point, err error) {
+				if o.Progress != nil {
+					o.Progress(runs+done, most, point, err)
+				}
+			},
+		}.Run()
+		if err != nil {
+			return err
+		}
+		runs += len(points) * max(o.Replications, 1)
+
+		for j, i := range toRun {
+			candidate := &candidates[i]
+			candidate.Row = rows[j]
+			candidate.Ran = true
+			candidate.MeetsSLA = rows[j].P95 <= o.SLA
+			if !candidate.MeetsSLA {
+				missed = append(missed, candidate.Config)
+			}
+		}
+		return nil
+	}
+
+	if err := run(mostOfEverything(candidates)); err != nil {
+		return nil, nil, err
+	}
+
+	explored := 0
+	met := false
+	for explored < len(candidates) && !met {
+		// the candidates at the next cost
+		start := explored
+		for explored < len(candidates) && candidates[explored].Cost == candidates[start].Cost {
+			explored++
+		}
+
+		toRun := []int{}
+		for i := start; i < explored; i++ {
+			if !candidates[i].Ran && !dominated(candidates[i].Config, missed) {
+				toRun = append(toRun, i)
+			}
+		}
+		if err := run(toRun); err != nil {
+			return nil, nil, err
+		}
+		for i := start; i < explored; i++ {
+			met = met || candidates[i].MeetsSLA
+		}
+	}
+
+	// the costs looked at and the points with the most of everything
+	looked := []Candidate{}
+	for i, candidate := range candidates {
+		if i < explored || candidate.Ran {
+			looked = append(looked, candidate)
+		}
+	}
+	markFrontier(looked)
+	var best *Candidate
+	for i, candidate := range looked {
+		if candidate.MeetsSLA && (best == nil || candidate.Cost < best.Cost ||
+			candidate.Cost == best.Cost && candidate.Row.P95 < best.Row.P95) {
+			best = &looked[i]
+		}
+	}
+
+	return best, looked, nil
+}
+
+// mostOfEverything is the candidates with at least as much of everything
+// as the others that are the same but for their staff and equipment.
+// A candidate isn't held to the ones that differ in other settings, so
+// sweeping one of them still has a most of everything for each value.
+func mostOfEverything(candidates []Candidate) []int {
+	result := []int{}
+	for i, candidate := range candidates {
+		most := true
+		for _, other := range candidates {
+			if !reflect.DeepEqual(withoutUnits(other.Config), withoutUnits(candidate.Config)) {
+				continue
+			}
+			if !noMoreThan(other.Config, candidate.Config) {
+				most = false
+				break
+			}
+		}
+		if most {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+// dominated is true when a config that missed the SLA has
+// at least as much of everything and is otherwise the same
+func dominated(config Config, missed []Config) bool {
+	for _, other := range missed {
+		if noMoreThan(config, other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noMoreThan is true when the config has no more of anything
+// than the other and is otherwise the same, configs that are
+// different in other ways can't be compared.  Each kind of grinder
+// and brewer is counted on its own, a french press doesn't make what
+// a drip brewer does.
+func noMoreThan(config Config, other Config) bool {
+	counts, otherCounts := unitCounts(config), unitCounts(other)
+	for i := range counts {
+		if counts[i] > otherCounts[i] {
+			return false
+		}
+	}
+
+	return reflect.DeepEqual(withoutUnits(config), withoutUnits(other))
+}
+
+// unitCounts is the count of each kind of staff and equipment
+// that's costed, the ones withoutUnits leaves out
+func unitCounts(config Config) []int {
+	return []int{
+		config.BaristaCount,
+		config.GrinderCount,
+		config.DecafGrinderCount,
+		config.BrewerCount,
+		config.PourOverCount,
+		config.FrenchPressCount,
+		config.AeroPressCount,
+		config.BatchBrewerCount,
+		config.KioskCount,
+	}
+}
+
+// withoutUnits is the config with none of the staff and equipment
+// that's costed, so configs can be compared on everything else
+func withoutUnits(config Config) Config {
+	config.BaristaCount = 0
+	config.GrinderCount = 0
+	config.DecafGrinderCount = 0
+	config.BrewerCount = 0
+	config.PourOverCount = 0
+	config.FrenchPressCount = 0
+	config.AeroPressCount = 0
+	config.BatchBrewerCount = 0
+	config.KioskCount = 0
+	return config
+}
+
+// markFrontier marks the candidates run that were the shortest
+// p95 wait for their cost or less, the candidates are cheapest first
+func markFrontier(candidates []Candidate) {
+	var shortest time.Duration
+	found := false
+	for start := 0; start < len(candidates); {
+		end := start
+		for end < len(candidates) && candidates[end].Cost == candidates[start].Cost {
+			end++
+		}
+
+		// the best of each cost is on the frontier if it
+		// beats everything cheaper
+		best := -1
+		for i := start; i < end; i++ {
+			if candidates[i].Ran && (best < 0 || candidates[i].Row.P95 < candidates[best].Row.P95) {
+				best = i
+			}
+		}
+		if best >= 0 && (!found || candidates[best].Row.P95 < shortest) {
+			candidates[best].Frontier = true
+			shortest = candidates[best].Row.P95
+			found = true
+		}
+		start = end
+	}
+}
@@ -0,0 +1,180 @@
+package sim
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testCandidates(baristas []int, brewerCounts []int) []Point {
+	points := []Point{}
+	for _, b := range baristas {
+		for _, br := range brewerCounts {
+			config := DefaultConfig()
+			config.CustomerCount = 1
+			config.Seed = 1
+			config.BaristaCount = b
+			config.BrewerCount = br
+			points = append(points, Point{Settings: []string{strconv.Itoa(b), strconv.Itoa(br)}, Config: config})
+		}
+	}
+	return points
+}
+
+func TestCostsOf(t *testing.T) {
+	costs := Costs{Barista: 20, Grinder: 2, Brewer: 3, Kiosk: 1}
+	config := DefaultConfig()
+	config.BaristaCount = 2
+	config.DecafGrinderCount = 1
+	config.FrenchPressCount = 1
+
+	// 2 baristas, 2 grinders, 2 brewers and a kiosk
+	assert.Equal(t, 51.0, costs.Of(config))
+}
+
+func TestDominated(t *testing.T) {
+	missed := DefaultConfig()
+	missed.BaristaCount = 2
+	missed.BrewerCount = 2
+
+	less := DefaultConfig()
+	assert.True(t, dominated(less, []Config{missed}))
+
+	more := DefaultConfig()
+	more.BaristaCount = 3
+	assert.False(t, dominated(more, []Config{missed}))
+
+	// a faster barista isn't comparable
+	faster := DefaultConfig()
+	faster.BaristaSpeed = 2
+	assert.False(t, dominated(faster, []Config{missed}))
+
+	// another kind of brewer or grinder isn't less of the same thing
+	frenchPress := DefaultConfig()
+	frenchPress.BrewerCount = 0
+	frenchPress.FrenchPressCount = 1
+	assert.False(t, dominated(frenchPress, []Config{missed}))
+	decaf := DefaultConfig()
+	decaf.DecafGrinderCount = 1
+	twoGrinders := missed
+	twoGrinders.GrinderCount = 2
+	assert.False(t, dominated(decaf, []Config{twoGrinders}))
+	assert.True(t, dominated(DefaultConfig(), []Config{twoGrinders}))
+}
+
+func TestMostOfEverythingMixedKinds(t *testing.T) {
+	drip := DefaultConfig()
+	frenchPress := DefaultConfig()
+	frenchPress.BrewerCount = 0
+	frenchPress.FrenchPressCount = 1
+	candidates := []Candidate{{Point: Point{Config: drip}}, {Point: Point{Config: frenchPress}}}
+
+	// neither has more of everything than the other
+	assert.Empty(t, mostOfEverything(candidates))
+}
+
+func TestMostOfEverything(t *testing.T) {
+	candidates := []Candidate{}
+	for _, point := range testCandidates([]int{1, 2}, []int{1, 2}) {
+		candidates = append(candidates, Candidate{Point: point})
+	}
+
+	assert.Equal(t, []int{3}, mostOfEverything(candidates))
+}
+
+// withSpeeds is the points at each of the barista speeds
+func withSpeeds(points []Point, speeds ...float64) []Point {
+	result := []Point{}
+	for _, speed := range speeds {
+		for _, point := range points {
+			point.Config.BaristaSpeed = speed
+			point.Settings = append(append([]string{}, point.Settings...), strconv.FormatFloat(speed, 'g', -1, 64))
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+func TestMostOfEverythingOtherSettings(t *testing.T) {
+	candidates := []Candidate{}
+	for _, point := range withSpeeds(testCandidates([]int{1, 2}, []int{1, 2}), 0.5, 1) {
+		candidates = append(candidates, Candidate{Point: point})
+	}
+
+	// the most of everything at each speed
+	assert.Equal(t, []int{3, 7}, mostOfEverything(candidates))
+}
+
+func TestMarkFrontier(t *testing.T) {
+	candidates := []Candidate{
+		{Cost: 1, Ran: true, Row: Row{P95: 50}},
+		{Cost: 2, Ran: true, Row: Row{P95: 60}},
+		{Cost: 2, Ran: true, Row: Row{P95: 40}},
+		{Cost: 3},
+		{Cost: 4, Ran: true, Row: Row{P95: 30}},
+	}
+	markFrontier(candidates)
+
+	frontier := []bool{}
+	for _, candidate := range candidates {
+		frontier = append(frontier, candidate.Frontier)
+	}
+	assert.Equal(t, []bool{true, false, true, false, true}, frontier)
+}
+
+func TestOptimizer(t *testing.T) {
+	costs := Costs{Barista: 20, Brewer: 3}
+
+	// everything meets a long SLA so the cheapest is picked
+	// after the one with the most of everything
+	best, explored, err := Optimizer{
+		Points:   testCandidates([]int{1, 2}, []int{1, 2}),
+		Costs:    costs,
+		SLA:      time.Hour,
+		Parallel: 2,
+	}.Run()
+	assert.NoError(t, err)
+	assert.NotNil(t, best)
+	assert.Equal(t, []string{"1", "1"}, best.Settings)
+	assert.Equal(t, 23.0, best.Cost)
+	assert.Len(t, explored, 2)
+	assert.Equal(t, 46.0, explored[1].Cost)
+	assert.True(t, explored[1].Ran)
+
+	// nothing meets no wait at all, the rest are skipped
+	runs := 0
+	best, explored, err = Optimizer{
+		Points:   testCandidates([]int{1, 2}, []int{1, 2}),
+		Costs:    costs,
+		SLA:      0,
+		Progress: func(int, int, Point, error) { runs++ },
+	}.Run()
+	assert.NoError(t, err)
+	assert.Nil(t, best)
+	assert.Equal(t, 1, runs)
+	assert.Len(t, explored, 4)
+	for _, candidate := range explored[:3] {
+		assert.False(t, candidate.Ran)
+	}
+	assert.True(t, explored[3].Frontier)
+
+	// sweeping the speed too still skips the ones with less
+	runs = 0
+	_, explored, err = Optimizer{
+		Points:   withSpeeds(testCandidates([]int{1, 2}, []int{1, 2}), 0.5, 1),
+		Costs:    costs,
+		SLA:      0,
+		Progress: func(int, int, Point, error) { runs++ },
+	}.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	skipped := 0
+	for _, candidate := range explored {
+		if !candidate.Ran {
+			skipped++
+		}
+	}
+	assert.Equal(t, 6, skipped)
+}
@@ -0,0 +1,273 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/queueing"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// the stations the queueing model predicts
+const (
+	GrinderStation = "grinder"
+	BrewerStation  = "brewer"
+)
+
+// Stations are the shop's grinders and brewers as queueing stations with
+// the customers arriving at random through the open hours.  The service
+// times come from the machines' steady speeds, the baristas' dosing and
+// the purges between varieties, with the timing spread's variation.
+// They don't count warm ups, remakes, shifts or the orders a barista can
+// hold, so they're closest to a shop without them.
+func (s *Shop) Stations() ([]queueing.Station, error) {
+	if s.Config.OpenHours <= 0 {
+		return nil, errors.New("the queueing model needs open hours for the customers to arrive through")
+	}
+	if s.Config.BatchBrewerCount > 0 {
+		return nil, errors.New("the queueing model doesn't cover orders poured from carafes")
+	}
+
+	arrivalRate := float64(len(s.Arrivals)) / (s.Config.OpenHours * 3600)
+	// a lognormal spread keeps the mean and scales the mean square
+	spread := math.Exp(s.Config.TimingSpread * s.Config.TimingSpread)
+
+	return []queueing.Station{
+		s.grinderStation(arrivalRate, spread),
+		s.brewerStation(arrivalRate, spread),
+	}, nil
+}
+
+// grinderStation has the grinder held while the barista doses the beans,
+// grinds them and purges the last variety when it's different.  Decaf goes
+// to the decaf grinders when there are some, and each order is as likely
+// to get any grinder that takes it and any of the baristas.
+func (s *Shop) grinderStation(arrivalRate float64, spread float64) queueing.Station {
+	hasDecaf := false
+	for _, spec := range s.grinderSpecs {
+		hasDecaf = hasDecaf || spec.decaf
+	}
+	takes := func(spec grinderSpec, item models.MenuItem) bool {
+		return spec.decaf == (hasDecaf && item.Variety.Decaf)
+	}
+
+	// the chance the last grind on a grinder that isn't dedicated was a
+	// different variety, an upper bound since the pool prefers a grinder
+	// with the variety already loaded
+	varieties := map[models.BeanVariety]float64{}
+	shared := 0.0
+	for _, arrival := range s.Arrivals {
+		if !(hasDecaf && arrival.Item.Variety.Decaf) {
+			varieties[arrival.Item.Variety]++
+			shared++
+		}
+	}
+	purgeChance := 0.0
+	if shared > 0 {
+		purgeChance = 1
+		for _, count := range varieties {
+			purgeChance -= (count / shared) * (count / shared)
+		}
+	}
+	purge := float64(models.DefaultPurgeSeconds)
+
+	mix := queueing.ServiceMix{}
+	for _, arrival := range s.Arrivals {
+		item := arrival.Item
+		grams := float64(item.CoffeeRatio.Coffee(item.Size))
+		grinders := []grinderSpec{}
+		for _, spec := range s.grinderSpecs {
+			if takes(spec, item) {
+				grinders = append(grinders, spec)
+			}
+		}
+
+		for _, spec := range grinders {
+			grind := grams / spec.gramsPerSecond * float64(models.DefaultRoastFactor(item.Variety.Roast)) / 100
+			chance := purgeChance
+			if spec.decaf {
+				chance = 0
+			}
+			for _, profile := range s.profiles {
+				held := models.SimSeconds(profile.DoseTime()) + grind
+				weight := 1 / float64(len(grinders)*len(s.profiles))
+				mix.AddMoments(weight,
+					held+chance*purge,
+					held*held+grind*grind*(spread-1)+2*chance*purge*held+chance*purge*purge)
+			}
+		}
+	}
+
+	return queueing.Station{
+		Name:        GrinderStation,
+		Servers:     len(s.grinderSpecs),
+		ArrivalRate: arrivalRate,
+		MeanService: mix.Mean(),
+		ServiceSCV:  mix.SCV(),
+	}
+}
+
+// brewerStation has a slot for each cup a brewer makes at once held for
+// the brew, each order is as likely to get any slot that can make it
+func (s *Shop) brewerStation(arrivalRate float64, spread float64) queueing.Station {
+	mix := queueing.ServiceMix{}
+	servers := 0
+	for _, spec := range s.brewerSpecs {
+		servers += spec.cups
+	}
+	for _, arrival := range s.Arrivals {
+		item := arrival.Item
+		slots := 0
+		for _, spec := range s.brewerSpecs {
+			if item.BrewableWith(spec.method) {
+				slots += spec.cups
+			}
+		}
+
+		for _, spec := range s.brewerSpecs {
+			if !item.BrewableWith(spec.method) {
+				continue
+			}
+			brew := models.SimSeconds(spec.fixed)
+			if spec.waterPerSecond > 0 {
+				brew += float64(item.Size) / spec.waterPerSecond
+			}
+			mix.AddMoments(float64(spec.cups)/float64(slots), brew, brew*brew*spread)
+		}
+	}
+
+	return queueing.Station{
+		Name:        BrewerStation,
+		Servers:     servers,
+		ArrivalRate: arrivalRate,
+		MeanService: mix.Mean(),
+		ServiceSCV:  mix.SCV(),
+	}
+}
+
+// StageWaits is a tracer that averages the spans by name, the
+// grinder and brewer waits are what the queueing model predicts
+type StageWaits struct {
+	lock  *sync.Mutex
+	total map[string]time.Duration
+	count map[string]int
+}
+
+func NewStageWaits() *StageWaits {
+	return &StageWaits{
+		lock:  &sync.Mutex{},
+		total: map[string]time.Duration{},
+		count: map[string]int{},
+	}
+}
+
+func (sw *StageWaits) Export(span models.Span) {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	sw.total[span.Name] += span.Duration()
+	sw.count[span.Name]++
+}
+
+// Mean is the average of the spans with the name
+func (sw *StageWaits) Mean(name string) time.Duration {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	if sw.count[name] == 0 {
+		return 0
+	}
+
+	return sw.total[name] / time.Duration(sw.count[name])
+}
+
+// QueueComparison is a queueing model's prediction for a
+// station next to what the simulation did
+type QueueComparison struct {
+	Station   queueing.Station
+	Model     string
+	Predicted queueing.Prediction
+	// Utilization is the station's busy time as a share of its servers
+	// through the open hours, the work for the day's customers over the
+	// time they came in.  Wait is the mean seconds an order waited.
+	Utilization float64
+	Wait        float64
+}
+
+// CompareQueues predicts each station with the M/M/c and M/G/c models
+// and puts the predictions next to the shop's run, the waits are the
+// ones traced by the shop's StageWaits.  The shop has to be closed.
+func (s *Shop) CompareQueues(waits *StageWaits) ([]QueueComparison, error) {
+	stations, err := s.Stations()
+	if err != nil {
+		return nil, err
+	}
+
+	busy := map[string]time.Duration{}
+	for _, usage := range s.Snapshot().Grinders {
+		busy[GrinderStation] += usage.Busy
+	}
+	for _, slot := range s.Stats().BrewerSlots {
+		busy[BrewerStation] += slot.Busy
+	}
+	spans := map[string]string{
+		GrinderStation: models.GrinderWaitSpan,
+		BrewerStation:  models.BrewerWaitSpan,
+	}
+
+	open := s.Config.OpenHours * float64(models.SimHour)
+	result := []QueueComparison{}
+	for _, station := range stations {
+		utilization := float64(busy[station.Name]) / (float64(station.Servers) * open)
+		wait := models.SimSeconds(waits.Mean(spans[station.Name]))
+		result = append(result,
+			QueueComparison{Station: station, Model: "M/M/c", Predicted: station.MMc(), Utilization: utilization, Wait: wait},
+			QueueComparison{Station: station, Model: "M/G/c", Predicted: station.MGc(), Utilization: utilization, Wait: wait})
+	}
+
+	return result, nil
+}
+
+// RelativeError is how far off the model is from the simulation as a
+// share of the simulated value
+func RelativeError(model float64, simulated float64) float64 {
+	if simulated == 0 {
+		if model == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+
+	return math.Abs(model-simulated) / simulated
+}
+
+// WriteQueueComparisons writes the predictions and simulated results as a
+// table, the waits in simulated seconds and the errors in percent
+func WriteQueueComparisons(w io.Writer, comparisons []QueueComparison) error {
+	_, err := fmt.Fprintf(w, "%-8s %-6s %7s %8s %8s %7s %9s %9s %7s\n",
+		"station", "model", "servers", "util", "sim util", "error", "wait", "sim wait", "error")
+	if err != nil {
+		return err
+	}
+
+	for _, comparison := range comparisons {
+		_, err := fmt.Fprintf(w, "%-8s %-6s %7d %7.1f%% %7.1f%% %6.1f%% %8.1fs %8.1fs %6.1f%%\n",
+			comparison.Station.Name,
+			comparison.Model,
+			comparison.Station.Servers,
+			100*comparison.Predicted.Utilization,
+			100*comparison.Utilization,
+			100*RelativeError(comparison.Predicted.Utilization, comparison.Utilization),
+			comparison.Predicted.Wait,
+			comparison.Wait,
+			100*RelativeError(comparison.Predicted.Wait, comparison.Wait))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// frenchPressConfig only makes the dark roast in french presses,
+// so there are no purges and every brew takes the same time
+func frenchPressConfig() Config {
+	config := DefaultConfig()
+	config.BrewerCount = 0
+	config.FrenchPressCount = 4
+	config.GrinderCount = 2
+	config.BaristaCount = 2
+	config.CustomerCount = 40
+	config.OpenHours = 0.25
+	config.BreakMinutes = 0
+	config.Seed = 3
+	return config
+}
+
+func TestStations(t *testing.T) {
+	shop, err := NewShop(frenchPressConfig())
+	assert.NoError(t, err)
+	shop.Close()
+
+	stations, err := shop.Stations()
+	assert.NoError(t, err)
+	assert.Len(t, stations, 2)
+
+	// a trained barista doses for 15 seconds then the dark roast
+	// grinds in 80% of the time
+	grinder := stations[0]
+	assert.Equal(t, GrinderStation, grinder.Name)
+	assert.Equal(t, 2, grinder.Servers)
+	assert.InDelta(t, 40.0/900, grinder.ArrivalRate, 1e-9)
+	total := 0.0
+	for _, arrival := range shop.Arrivals {
+		grams := float64(arrival.Item.CoffeeRatio.Coffee(arrival.Item.Size))
+		for _, spec := range shop.grinderSpecs {
+			total += (15 + grams/spec.gramsPerSecond*0.8) / 2
+		}
+	}
+	assert.InDelta(t, total/40, grinder.MeanService, 1e-9)
+	assert.Greater(t, grinder.ServiceSCV, 0.0)
+
+	// french presses steep 4 minutes with 4 cups in each
+	brewer := stations[1]
+	assert.Equal(t, BrewerStation, brewer.Name)
+	assert.Equal(t, 16, brewer.Servers)
+	assert.InDelta(t, 240, brewer.MeanService, 1e-9)
+	assert.InDelta(t, 0, brewer.ServiceSCV, 1e-9)
+}
+
+func TestStationsSpread(t *testing.T) {
+	config := frenchPressConfig()
+	config.TimingSpread = 0.5
+	shop, err := NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+
+	stations, err := shop.Stations()
+	assert.NoError(t, err)
+	assert.InDelta(t, 240, stations[1].MeanService, 1e-9)
+	assert.InDelta(t, math.Exp(0.25)-1, stations[1].ServiceSCV, 1e-9)
+}
+
+func TestStationsErrors(t *testing.T) {
+	config := frenchPressConfig()
+	config.OpenHours = 0
+	shop, err := NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+	_, err = shop.Stations()
+	assert.Error(t, err)
+
+	config = frenchPressConfig()
+	config.BatchBrewerCount = 1
+	shop, err = NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+	_, err = shop.Stations()
+	assert.Error(t, err)
+}
+
+func TestStageWaits(t *testing.T) {
+	waits := NewStageWaits()
+	start := time.Now()
+	waits.Export(models.Span{Name: models.GrinderWaitSpan, Start: start, End: start.Add(time.Second)})
+	waits.Export(models.Span{Name: models.GrinderWaitSpan, Start: start, End: start.Add(3 * time.Second)})
+	assert.Equal(t, 2*time.Second, waits.Mean(models.GrinderWaitSpan))
+	assert.Equal(t, time.Duration(0), waits.Mean(models.BrewerWaitSpan))
+}
+
+func TestCompareQueues(t *testing.T) {
+	waits := NewStageWaits()
+	shop, err := NewShop(frenchPressConfig(), models.WithTracer(waits))
+	assert.NoError(t, err)
+	shop.SendCustomers()
+	shop.Close()
+
+	comparisons, err := shop.CompareQueues(waits)
+	assert.NoError(t, err)
+	assert.Len(t, comparisons, 4)
+	for _, comparison := range comparisons {
+		assert.InDelta(t, comparison.Predicted.Utilization, comparison.Utilization, 0.15,
+			"%s %s", comparison.Station.Name, comparison.Model)
+	}
+	assert.Equal(t, "M/M/c", comparisons[0].Model)
+	assert.Equal(t, "M/G/c", comparisons[1].Model)
+
+	table := &strings.Builder{}
+	assert.NoError(t, WriteQueueComparisons(table, comparisons))
+	assert.Len(t, strings.Split(strings.TrimSpace(table.String()), "\n"), 5)
+}
+
+func TestRelativeError(t *testing.T) {
+	assert.InDelta(t, 0.5, RelativeError(3, 2), 1e-9)
+	assert.InDelta(t, 0.5, RelativeError(1, 2), 1e-9)
+	assert.Equal(t, 0.0, RelativeError(0, 0))
+	assert.True(t, math.IsInf(RelativeError(1, 0), 1))
+}
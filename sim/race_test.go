@@ -0,0 +1,7 @@
+//go:build race
+
+package sim
+
+// the race detector shuffles the goroutines so the golden runs
+// aren't repeatable under it
+const raceEnabled = true
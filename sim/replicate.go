@@ -0,0 +1,238 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Statistic is a number a run of the simulation reports
+type Statistic struct {
+	Name string
+	Of   func(Result) float64
+}
+
+// Statistics are what the replications estimate, times
+// are in simulated seconds
+var Statistics = []Statistic{
+	{Name: "orders", Of: func(r Result) float64 { return float64(r.Stats.OrdersServed) }},
+	{Name: "throughput_per_hour", Of: Result.Throughput},
+	{Name: "avg_wait_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.AverageWait()) }},
+	{Name: "p50_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.WaitPercentile(50)) }},
+	{Name: "p90_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.WaitPercentile(90)) }},
+	{Name: "p95_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.WaitPercentile(95)) }},
+	{Name: "p99_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.WaitPercentile(99)) }},
+	{Name: "max_wait_seconds", Of: func(r Result) float64 { return models.SimSeconds(r.Stats.MaxWait) }},
+	{Name: "avg_quality", Of: func(r Result) float64 { return r.Stats.AverageQuality() }},
+}
+
+// Estimate is a statistic's mean over the replications and the half
+// width of its 95% confidence interval, the interval is the mean give
+// or take the half width
+type Estimate struct {
+	Name         string
+	Replications int
+	Mean         float64
+	HalfWidth    float64
+}
+
+// NewEstimate is the mean of the samples with a confidence interval from
+// the t distribution, one sample has no interval so it's infinite
+func NewEstimate(name string, samples []float64) Estimate {
+	result := Estimate{Name: name, Replications: len(samples)}
+	if len(samples) == 0 {
+		return result
+	}
+
+	for _, sample := range samples {
+		result.Mean += sample
+	}
+	result.Mean /= float64(len(samples))
+	if len(samples) == 1 {
+		result.HalfWidth = math.Inf(1)
+		return result
+	}
+
+	variance := 0.0
+	for _, sample := range samples {
+		variance += (sample - result.Mean) * (sample - result.Mean)
+	}
+	variance /= float64(len(samples) - 1)
+	result.HalfWidth = tQuantile(len(samples)-1) * math.Sqrt(variance/float64(len(samples)))
+
+	return result
+}
+
+func (e Estimate) Low() float64 {
+	return e.Mean - e.HalfWidth
+}
+
+func (e Estimate) High() float64 {
+	return e.Mean + e.HalfWidth
+}
+
+// Excludes is true when the interval doesn't have the value in it, for a
+// difference that excludes 0 the configs really are different
+func (e Estimate) Excludes(value float64) bool {
+	return value < e.Low() || value > e.High()
+}
+
+// the 97.5th percentile of the t distribution for 1 to 30 degrees
+// of freedom, the two sided 95% confidence interval
+var tQuantiles = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tQuantile is from the table up to 30 degrees of freedom,
+// past that it closes in on the normal distribution's 1.96
+func tQuantile(degrees int) float64 {
+	if degrees <= len(tQuantiles) {
+		return tQuantiles[degrees-1]
+	}
+
+	return 1.96 + 2.5/float64(degrees)
+}
+
+// Replications runs a config again and again, each with the next seed
+type Replications struct {
+	Config   Config
+	Count    int
+	Parallel int
+	// Progress is told about each run as it finishes, it can be nil
+	Progress func(done int, total int, err error)
+}
+
+// Run returns the results of every run in seed order
+func (r Replications) Run() ([]Result, error) {
+	results, err := runPoints([]Point{{Config: r.Config}}, r.Count, r.Parallel,
+		func(done int, total int, point Point, err error) {
+			if r.Progress != nil {
+				r.Progress(done, total, err)
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// Summarize estimates each of the statistics from the results
+func Summarize(results []Result) []Estimate {
+	estimates := []Estimate{}
+	for _, statistic := range Statistics {
+		samples := []float64{}
+		for _, result := range results {
+			samples = append(samples, statistic.Of(result))
+		}
+		estimates = append(estimates, NewEstimate(statistic.Name, samples))
+	}
+
+	return estimates
+}
+
+// Comparison is a statistic for two configs and the difference of the
+// second from the first, estimated run for run
+type Comparison struct {
+	Base       Estimate
+	Other      Estimate
+	Difference Estimate
+}
+
+// Compare pairs the runs of two configs with the same seed, they share
+// their customers and machines so the noise that's the same in both
+// drops out of the difference.  The intervals are narrower than
+// comparing the two sets of runs as a whole.
+func Compare(base []Result, other []Result) ([]Comparison, error) {
+	if len(base) != len(other) {
+		return nil, fmt.Errorf("can't pair %d runs with %d", len(base), len(other))
+	}
+	for i := range base {
+		if base[i].Config.Seed != other[i].Config.Seed {
+			return nil, fmt.Errorf("run %d has seeds %d and %d, the pairs need the same seed",
+				i, base[i].Config.Seed, other[i].Config.Seed)
+		}
+	}
+
+	comparisons := []Comparison{}
+	for _, statistic := range Statistics {
+		baseSamples := []float64{}
+		otherSamples := []float64{}
+		differences := []float64{}
+		for i := range base {
+			baseSamples = append(baseSamples, statistic.Of(base[i]))
+			otherSamples = append(otherSamples, statistic.Of(other[i]))
+			differences = append(differences, statistic.Of(other[i])-statistic.Of(base[i]))
+		}
+		comparisons = append(comparisons, Comparison{
+			Base:       NewEstimate(statistic.Name, baseSamples),
+			Other:      NewEstimate(statistic.Name, otherSamples),
+			Difference: NewEstimate(statistic.Name, differences),
+		})
+	}
+
+	return comparisons, nil
+}
+
+// ComparePaired runs both configs with the same seeds and compares them
+func ComparePaired(base Config, other Config, replications int, parallel int,
+	progress func(done int, total int, err error)) ([]Comparison, error) {
+	other.Seed = base.Seed
+	results, err := runPoints([]Point{{Config: base}, {Config: other}}, replications, parallel,
+		func(done int, total int, point Point, err error) {
+			if progress != nil {
+				progress(done, total, err)
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(results[0], results[1])
+}
+
+// WriteEstimates writes the estimates as a table
+func WriteEstimates(w io.Writer, estimates []Estimate) error {
+	_, err := fmt.Fprintf(w, "%-20s %12s %12s   %s\n", "statistic", "mean", "± 95%", "interval")
+	if err != nil {
+		return err
+	}
+
+	for _, estimate := range estimates {
+		_, err := fmt.Fprintf(w, "%-20s %12.3f %12.3f   [%.3f, %.3f]\n",
+			estimate.Name, estimate.Mean, estimate.HalfWidth, estimate.Low(), estimate.High())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteComparisons writes both configs' means and the difference as a
+// table, the differences whose interval excludes 0 are marked with a *
+func WriteComparisons(w io.Writer, comparisons []Comparison) error {
+	_, err := fmt.Fprintf(w, "%-20s %12s %12s %12s %12s   %s\n", "statistic", "base", "other", "difference", "± 95%", "interval")
+	if err != nil {
+		return err
+	}
+
+	for _, comparison := range comparisons {
+		difference := comparison.Difference
+		mark := ""
+		if difference.Excludes(0) {
+			mark = " *"
+		}
+		_, err := fmt.Fprintf(w, "%-20s %12.3f %12.3f %12.3f %12.3f   [%.3f, %.3f]%s\n",
+			difference.Name, comparison.Base.Mean, comparison.Other.Mean,
+			difference.Mean, difference.HalfWidth, difference.Low(), difference.High(), mark)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
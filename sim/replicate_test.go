@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEstimate(t *testing.T) {
+	estimate := NewEstimate("orders", []float64{1, 2, 3, 4, 5})
+	assert.Equal(t, 5, estimate.Replications)
+	assert.InDelta(t, 3, estimate.Mean, 1e-9)
+	// the standard deviation is sqrt(2.5) and t is 2.776 for 4 degrees
+	assert.InDelta(t, 2.776*math.Sqrt(2.5/5), estimate.HalfWidth, 1e-9)
+	assert.True(t, estimate.Excludes(0))
+	assert.False(t, estimate.Excludes(3))
+
+	single := NewEstimate("orders", []float64{7})
+	assert.InDelta(t, 7, single.Mean, 1e-9)
+	assert.True(t, math.IsInf(single.HalfWidth, 1))
+	assert.False(t, single.Excludes(0))
+
+	assert.Equal(t, Estimate{Name: "orders"}, NewEstimate("orders", nil))
+}
+
+func TestTQuantile(t *testing.T) {
+	assert.InDelta(t, 12.706, tQuantile(1), 1e-9)
+	assert.InDelta(t, 2.042, tQuantile(30), 1e-9)
+	assert.InDelta(t, 2.0, tQuantile(60), 0.005)
+	assert.InDelta(t, 1.98, tQuantile(120), 0.005)
+}
+
+func TestCompare(t *testing.T) {
+	result := func(seed int64, orders int) Result {
+		return Result{Config: Config{Seed: seed}, Stats: models.Stats{OrdersServed: orders}}
+	}
+	// the runs are noisy but the other is always 2 more
+	base := []Result{result(1, 10), result(2, 20), result(3, 15)}
+	other := []Result{result(1, 12), result(2, 22), result(3, 17)}
+
+	comparisons, err := Compare(base, other)
+	assert.NoError(t, err)
+	assert.Len(t, comparisons, len(Statistics))
+	orders := comparisons[0]
+	assert.Equal(t, "orders", orders.Difference.Name)
+	assert.InDelta(t, 15, orders.Base.Mean, 1e-9)
+	assert.InDelta(t, 17, orders.Other.Mean, 1e-9)
+	assert.InDelta(t, 2, orders.Difference.Mean, 1e-9)
+	assert.InDelta(t, 0, orders.Difference.HalfWidth, 1e-9)
+	assert.True(t, orders.Difference.Excludes(0))
+	// unpaired the noise would hide it
+	assert.False(t, orders.Base.Excludes(orders.Other.Mean))
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, WriteComparisons(out, comparisons))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, len(Statistics)+1)
+	assert.True(t, strings.HasSuffix(lines[1], "*"))
+
+	_, err = Compare(base, other[:2])
+	assert.Error(t, err)
+	_, err = Compare(base, []Result{result(1, 12), result(2, 22), result(4, 17)})
+	assert.Error(t, err)
+}
+
+func TestReplications(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 2
+	config.Seed = 5
+
+	done := 0
+	results, err := Replications{
+		Config:   config,
+		Count:    3,
+		Parallel: 2,
+		Progress: func(int, int, error) { done++ },
+	}.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, done)
+	assert.Len(t, results, 3)
+	for i, result := range results {
+		assert.Equal(t, int64(5+i), result.Config.Seed)
+		assert.Equal(t, 2, result.Stats.OrdersServed)
+	}
+
+	estimates := Summarize(results)
+	assert.Len(t, estimates, len(Statistics))
+	assert.Equal(t, Estimate{Name: "orders", Replications: 3, Mean: 2}, estimates[0])
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, WriteEstimates(out, estimates))
+	assert.Contains(t, out.String(), "throughput_per_hour")
+}
+
+func TestComparePaired(t *testing.T) {
+	base := DefaultConfig()
+	base.CustomerCount = 2
+	base.Seed = 5
+	other := base
+	other.GrinderCount = 2
+	other.Seed = 99
+
+	comparisons, err := ComparePaired(base, other, 2, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, comparisons[0].Difference.Replications)
+	assert.InDelta(t, 0, comparisons[0].Difference.Mean, 1e-9)
+}
+
+func TestReplicationsPickOneSeed(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 2
+
+	// the seed from the clock is picked once and reported
+	results, err := Replications{Config: config, Count: 3, Parallel: 3}.Run()
+	assert.NoError(t, err)
+	assert.NotZero(t, results[0].Config.Seed)
+	for i, result := range results {
+		assert.Equal(t, results[0].Config.Seed+int64(i), result.Config.Seed)
+	}
+
+	// both configs of a comparison run with the same seeds
+	other := config
+	other.GrinderCount = 2
+	paired, err := runPoints([]Point{{Config: config}, {Config: other}}, 2, 2, nil)
+	assert.NoError(t, err)
+	for i := range paired[0] {
+		assert.NotZero(t, paired[0][i].Config.Seed)
+		assert.Equal(t, paired[0][i].Config.Seed, paired[1][i].Config.Seed)
+	}
+}
@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Result is how a run of the simulation went
+type Result struct {
+	Config  Config
+	Stats   models.Stats
+	RunTime time.Duration
+}
+
+// Throughput is the orders served per simulated hour the shop was open
+func (r Result) Throughput() float64 {
+	if r.Stats.OpenTime <= 0 {
+		return 0
+	}
+
+	return float64(r.Stats.OrdersServed) / (float64(r.Stats.OpenTime) / float64(models.SimHour))
+}
+
+// SendCustomers sends each customer in at their arrival time and
+// waits for them all to get their coffee
+func (s *Shop) SendCustomers() {
+	// the arrivals and anything played against the shop
+	// are timed from the same start
+	start := time.Now()
+	for _, started := range s.starts {
+		started(start)
+	}
+
+	orderWaitGroup := sync.WaitGroup{}
+	orderWaitGroup.Add(len(s.Arrivals))
+	for _, arrival := range s.Arrivals {
+		go func() {
+			defer orderWaitGroup.Done()
+
+			// model the customer
+			// arrive some time in the day
+			// wait for turn to order
+			// order their coffee
+			// leave the kiosk for the next person
+			time.Sleep(time.Until(start.Add(arrival.At)))
+			kiosk := s.WaitForOrderingKiosk()
+			if kiosk == nil {
+				return
+			}
+			order := kiosk.CreateOrder(arrival.Customer, arrival.Item)
+			s.LeaveOrderingKiosk(kiosk)
+			if order == nil {
+				return
+			}
+
+			order.Wait()
+			fmt.Println(arrival.Customer + " says Thank You")
+		}()
+	}
+
+	fmt.Println("Waiting for all customers to order...")
+
+	// wait for orders to be put in before the shop is closed
+	orderWaitGroup.Wait()
+	fmt.Println("Customers have all ordered.")
+}
+
+// Run builds the shop for the config, sends in the customers
+// and closes the shop once they're all served
+func Run(c Config, opts ...models.ShopOption) (Result, error) {
+	shop, err := NewShop(c, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+
+	start := time.Now()
+	shop.SendCustomers()
+	shop.Close()
+
+	return Result{
+		Config:  shop.Config,
+		Stats:   shop.Stats(),
+		RunTime: time.Since(start),
+	}, nil
+}
@@ -0,0 +1,530 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Action is something done to the shop at a time after it opens.  The
+// time is simulated, the Go duration 10m is ten simulated minutes.
+//
+//	at 10m break-machine Grinder-1
+//	at 12m repair-machine Grinder-1
+//	at 15m arrive 30                    a burst of customers off the menu
+//	at 15m arrive 10 "Pour Over" over 2m
+//	at 20m barista-break Barista-0 5m
+//	at 20m add-barista [name]
+//	at 25m remove-barista Barista-1
+//	at 30m close-kiosk
+//	at 35m open-kiosk
+//	at 40m sold-out "Pour Over"
+//	at 45m restock "Pour Over"
+type Action struct {
+	Line int
+	At   time.Duration
+	Verb string
+	Args []string
+}
+
+// the actions' verbs and their smallest and largest counts of args
+var actionArgs = map[string][2]int{
+	"break-machine":  {1, 1},
+	"repair-machine": {1, 1},
+	"arrive":         {1, 4},
+	"barista-break":  {2, 2},
+	"add-barista":    {0, 1},
+	"remove-barista": {1, 1},
+	"open-kiosk":     {0, 0},
+	"close-kiosk":    {0, 0},
+	"sold-out":       {1, 1},
+	"restock":        {1, 1},
+}
+
+// Expectation is a check of a statistic once the scenario's run is over,
+// like expect p95_seconds <= 600.  The statistics are the ones the
+// replications estimate and the scenario's counts.
+type Expectation struct {
+	Line      int
+	Statistic string
+	Op        string
+	Value     float64
+}
+
+var expectationOps = map[string]func(float64, float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// Check is an error when the result doesn't meet the expectation
+func (e Expectation) Check(result Result) error {
+	statistic, _ := findStatistic(e.Statistic)
+	actual := statistic.Of(result)
+	if !expectationOps[e.Op](actual, e.Value) {
+		return fmt.Errorf("line %d: expected %s %s %g but it was %g", e.Line, e.Statistic, e.Op, e.Value, actual)
+	}
+
+	return nil
+}
+
+// the counts a scenario can expect on top of the Statistics
+var scenarioStatistics = []Statistic{
+	{Name: "turned_away", Of: func(r Result) float64 { return float64(r.Stats.TurnedAway) }},
+	{Name: "remakes", Of: func(r Result) float64 { return float64(r.Stats.Remakes) }},
+	{Name: "handoffs", Of: func(r Result) float64 { return float64(r.Stats.Handoffs) }},
+}
+
+func findStatistic(name string) (Statistic, bool) {
+	for _, statistic := range append(Statistics, scenarioStatistics...) {
+		if statistic.Name == name {
+			return statistic, true
+		}
+	}
+
+	return Statistic{}, false
+}
+
+// Scenario is a script of actions done to a running shop and the
+// expectations of how it does, one to a line.  A # starts a comment
+// and args with spaces are in double quotes.
+type Scenario struct {
+	Actions      []Action
+	Expectations []Expectation
+}
+
+// LoadScenario reads a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scenario, err := ParseScenario(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario reads the actions and expectations
+func ParseScenario(r io.Reader) (*Scenario, error) {
+	result := &Scenario{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields, err := splitFields(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "at":
+			action, err := parseAction(line, fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			result.Actions = append(result.Actions, action)
+		case "expect":
+			expectation, err := parseExpectation(line, fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			result.Expectations = append(result.Expectations, expectation)
+		default:
+			return nil, fmt.Errorf("line %d: %q isn't at or expect", line, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseAction(line int, fields []string) (Action, error) {
+	if len(fields) < 2 {
+		return Action{}, errors.New("an action is at a time and a verb")
+	}
+	at, err := parseSimDuration(fields[0])
+	if err != nil {
+		return Action{}, err
+	}
+	action := Action{Line: line, At: at, Verb: fields[1], Args: fields[2:]}
+
+	counts, found := actionArgs[action.Verb]
+	if !found {
+		return Action{}, fmt.Errorf("no %s action", action.Verb)
+	}
+	if len(action.Args) < counts[0] || len(action.Args) > counts[1] {
+		return Action{}, fmt.Errorf("%s takes %d to %d args", action.Verb, counts[0], counts[1])
+	}
+
+	switch action.Verb {
+	case "arrive":
+		_, _, _, err = action.burst()
+	case "barista-break":
+		_, err = parseSimDuration(action.Args[1])
+	}
+	return action, err
+}
+
+func parseExpectation(line int, fields []string) (Expectation, error) {
+	if len(fields) != 3 {
+		return Expectation{}, errors.New("an expectation is a statistic, a comparison and a value")
+	}
+	if _, found := findStatistic(fields[0]); !found {
+		return Expectation{}, fmt.Errorf("no %s statistic", fields[0])
+	}
+	if _, found := expectationOps[fields[1]]; !found {
+		return Expectation{}, fmt.Errorf("%q isn't a comparison", fields[1])
+	}
+	value, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return Expectation{}, err
+	}
+
+	return Expectation{Line: line, Statistic: fields[0], Op: fields[1], Value: value}, nil
+}
+
+// burst is the count of customers arriving, the item they all order or
+// empty for anything off the menu and the time they're spread over
+func (a Action) burst() (int, string, time.Duration, error) {
+	count, err := strconv.Atoi(a.Args[0])
+	if err != nil || count < 1 {
+		return 0, "", 0, fmt.Errorf("%q isn't a count of customers", a.Args[0])
+	}
+
+	item := ""
+	var over time.Duration
+	args := a.Args[1:]
+	if len(args) == 1 || len(args) == 3 {
+		item = args[0]
+		args = args[1:]
+	}
+	if len(args) == 2 {
+		if args[0] != "over" {
+			return 0, "", 0, fmt.Errorf("%q isn't over a time", args[0])
+		}
+		if over, err = parseSimDuration(args[1]); err != nil {
+			return 0, "", 0, err
+		}
+	}
+
+	return count, item, over, nil
+}
+
+// parseSimDuration reads a Go duration as simulated time
+func parseSimDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s is before the shop opens", s)
+	}
+
+	return d / (time.Second / models.SimSecond), nil
+}
+
+// splitFields splits the line on spaces, leaving out the comment
+// and keeping the spaces in double quoted fields
+func splitFields(line string) ([]string, error) {
+	fields := []string{}
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" || line[0] == '#' {
+			return fields, nil
+		}
+
+		if line[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("unfinished quote in %s", line)
+			}
+			field, _ := strconv.Unquote(quoted)
+			fields = append(fields, field)
+			line = line[len(quoted):]
+			continue
+		}
+
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+}
+
+// Playback is a scenario's actions being applied to a shop
+type Playback struct {
+	lock     *sync.Mutex
+	timers   []*time.Timer
+	failures []error
+}
+
+// machine is a grinder or brewer a scenario can break,
+// makes is true for the items it can do its part of
+type machine struct {
+	grinder bool
+	makes   func(models.MenuItem) bool
+}
+
+// shopMachines is the shop's grinders and brewers by name, the
+// snapshot has them in the order they were built from their specs
+func shopMachines(shop *Shop) map[string]machine {
+	machines := map[string]machine{}
+	snapshot := shop.Snapshot()
+	for i, usage := range snapshot.Grinders {
+		decaf := shop.grinderSpecs[i].decaf
+		machines[usage.Grinder] = machine{grinder: true, makes: func(item models.MenuItem) bool {
+			return !decaf || item.Variety == Decaf
+		}}
+	}
+	built := 0
+	for _, slot := range snapshot.Brewers {
+		if _, found := machines[slot.Brewer]; found {
+			continue
+		}
+		method := shop.brewerSpecs[built].method
+		built++
+		machines[slot.Brewer] = machine{makes: func(item models.MenuItem) bool {
+			return item.BrewableWith(method)
+		}}
+	}
+
+	return machines
+}
+
+// makeable is true when a grinder and a brewer that aren't
+// broken can make the item
+func makeable(machines map[string]machine, broken map[string]bool, item models.MenuItem) bool {
+	grinds, brews := false, false
+	for name, m := range machines {
+		if broken[name] || !m.makes(item) {
+			continue
+		}
+		grinds = grinds || m.grinder
+		brews = brews || !m.grinder
+	}
+
+	return grinds && brews
+}
+
+// checkBreakdowns is an error for a machine breaking that leaves nothing
+// to make an item on with no repair coming, the customers who order it
+// would wait forever
+func checkBreakdowns(actions []Action, machines map[string]machine, menu models.Menu) error {
+	actions = slices.Clone(actions)
+	slices.SortStableFunc(actions, func(a, b Action) int {
+		return cmp.Compare(a.At, b.At)
+	})
+
+	broken := map[string]bool{}
+	for i, action := range actions {
+		switch action.Verb {
+		case "repair-machine":
+			delete(broken, action.Args[0])
+			continue
+		case "break-machine":
+		default:
+			continue
+		}
+
+		broken[action.Args[0]] = true
+		for _, item := range menu {
+			if !makeable(machines, nil, item) || makeable(machines, broken, item) {
+				continue
+			}
+			// the repairs to come have to bring back what's missing
+			repaired := maps.Clone(broken)
+			for _, later := range actions[i+1:] {
+				if later.Verb == "repair-machine" {
+					delete(repaired, later.Args[0])
+				}
+			}
+			if !makeable(machines, repaired, item) {
+				return fmt.Errorf("line %d: nothing can make %s once %s breaks and it's never repaired",
+					action.Line, item.Name, action.Args[0])
+			}
+		}
+	}
+
+	return nil
+}
+
+// Play checks the actions against the shop, adds the bursts of customers
+// to the ones coming in and applies the rest at their times from when
+// the shop's customers start coming in, the same start as the arrivals.
+// A machine can't break for good if it's the last one that can make an
+// item.  Play the scenario before sending in the customers.
+func (sc *Scenario) Play(shop *Shop) (*Playback, error) {
+	machines := shopMachines(shop)
+	items := map[string]models.MenuItem{}
+	for _, item := range shop.Menu {
+		items[item.Name] = item
+	}
+
+	// the bursts are the same customers for a seed
+	rng := rand.New(rand.NewSource(shop.Config.Seed))
+	playback := &Playback{lock: &sync.Mutex{}}
+	actions := []Action{}
+	for _, action := range sc.Actions {
+		switch action.Verb {
+		case "break-machine", "repair-machine":
+			if _, found := machines[action.Args[0]]; !found {
+				return nil, fmt.Errorf("line %d: no %s in the shop", action.Line, action.Args[0])
+			}
+		case "sold-out", "restock":
+			if _, found := items[action.Args[0]]; !found {
+				return nil, fmt.Errorf("line %d: no %s on the menu", action.Line, action.Args[0])
+			}
+		case "arrive":
+			count, name, over, _ := action.burst()
+			item, found := items[name]
+			if name != "" && !found {
+				return nil, fmt.Errorf("line %d: no %s on the menu", action.Line, name)
+			}
+			for i := 0; i < count; i++ {
+				if name == "" {
+					item = shop.Menu[rng.Intn(len(shop.Menu))]
+				}
+				shop.Arrivals = append(shop.Arrivals, Arrival{
+					At:       action.At + over*time.Duration(i)/time.Duration(count),
+					Customer: fmt.Sprintf("Burst-%d-%d", action.Line, i),
+					Item:     item,
+				})
+			}
+			continue
+		}
+		actions = append(actions, action)
+	}
+	if err := checkBreakdowns(actions, machines, shop.Menu); err != nil {
+		return nil, err
+	}
+
+	shop.starts = append(shop.starts, func(start time.Time) {
+		playback.lock.Lock()
+		defer playback.lock.Unlock()
+
+		for _, action := range actions {
+			playback.timers = append(playback.timers, time.AfterFunc(time.Until(start.Add(action.At)), func() {
+				if err := action.apply(shop); err != nil {
+					fmt.Println("Scenario", err)
+					playback.lock.Lock()
+					playback.failures = append(playback.failures, err)
+					playback.lock.Unlock()
+				}
+			}))
+		}
+	})
+
+	return playback, nil
+}
+
+func (a Action) apply(shop *Shop) error {
+	fmt.Printf("Scenario line %d: %s %s\n", a.Line, a.Verb, strings.Join(a.Args, " "))
+	done := true
+	switch a.Verb {
+	case "break-machine":
+		done = shop.BreakMachine(a.Args[0])
+	case "repair-machine":
+		done = shop.RepairMachine(a.Args[0])
+	case "barista-break":
+		length, _ := parseSimDuration(a.Args[1])
+		done = shop.TakeBreak(a.Args[0], length)
+	case "add-barista":
+		profile := models.DefaultBaristaProfile("")
+		if len(a.Args) > 0 {
+			profile.Name = a.Args[0]
+		}
+		profile.Speed = shop.Config.BaristaSpeed
+		profile.ErrorRate = shop.Config.BaristaErrorRate
+		done = shop.AddBarista(profile) != ""
+	case "remove-barista":
+		done = shop.RemoveBarista(a.Args[0])
+	case "open-kiosk":
+		done = shop.OpenKiosk()
+	case "close-kiosk":
+		shop.CloseKiosk()
+	case "sold-out":
+		done = shop.SetSoldOut(a.Args[0], true)
+	case "restock":
+		done = shop.SetSoldOut(a.Args[0], false)
+	}
+
+	if !done {
+		return fmt.Errorf("line %d: couldn't %s %s", a.Line, a.Verb, strings.Join(a.Args, " "))
+	}
+	return nil
+}
+
+// Stop drops the actions that haven't happened yet and returns
+// the ones that couldn't be done
+func (p *Playback) Stop() []error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, timer := range p.timers {
+		timer.Stop()
+	}
+
+	return append([]error{}, p.failures...)
+}
+
+// Check is the expectations the result doesn't meet
+func (sc *Scenario) Check(result Result) []error {
+	failures := []error{}
+	for _, expectation := range sc.Expectations {
+		if err := expectation.Check(result); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	return failures
+}
+
+// RunScenario runs the config with the scenario played against it and
+// returns how it went with the actions that couldn't be done and the
+// expectations it didn't meet
+func RunScenario(c Config, scenario *Scenario, opts ...models.ShopOption) (Result, []error, error) {
+	shop, err := NewShop(c, opts...)
+	if err != nil {
+		return Result{}, nil, err
+	}
+	playback, err := scenario.Play(shop)
+	if err != nil {
+		shop.Close()
+		return Result{}, nil, err
+	}
+
+	start := time.Now()
+	shop.SendCustomers()
+	shop.Close()
+	failures := playback.Stop()
+
+	result := Result{
+		Config:  shop.Config,
+		Stats:   shop.Stats(),
+		RunTime: time.Since(start),
+	}
+	return result, append(failures, scenario.Check(result)...), nil
+}
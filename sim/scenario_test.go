@@ -0,0 +1,199 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scenarioConfig is a small shop open for 6 minutes,
+// the scenarios in testdata are played against it
+func scenarioConfig() Config {
+	config := DefaultConfig()
+	config.GrinderCount = 2
+	config.BaristaCount = 2
+	config.CustomerCount = 10
+	config.OpenHours = 0.1
+	config.BreakMinutes = 0
+	config.Seed = 7
+	return config
+}
+
+func TestParseScenario(t *testing.T) {
+	scenario, err := ParseScenario(strings.NewReader(`
+# a comment
+at 1m30s break-machine Grinder-0   # broken
+at 2m arrive 10 "Large Regular" over 1m
+at 0s sold-out Decaf
+at 3h close-kiosk
+
+expect p95_seconds <= 600
+expect turned_away == 0
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Action{
+		{Line: 3, At: 90 * models.SimSecond, Verb: "break-machine", Args: []string{"Grinder-0"}},
+		{Line: 4, At: 120 * models.SimSecond, Verb: "arrive", Args: []string{"10", "Large Regular", "over", "1m"}},
+		{Line: 5, At: 0, Verb: "sold-out", Args: []string{"Decaf"}},
+		{Line: 6, At: 3 * models.SimHour, Verb: "close-kiosk", Args: []string{}},
+	}, scenario.Actions)
+	assert.Equal(t, []Expectation{
+		{Line: 8, Statistic: "p95_seconds", Op: "<=", Value: 600},
+		{Line: 9, Statistic: "turned_away", Op: "==", Value: 0},
+	}, scenario.Expectations)
+
+	count, item, over, err := scenario.Actions[1].burst()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, count)
+	assert.Equal(t, "Large Regular", item)
+	assert.Equal(t, 60*models.SimSecond, over)
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	for _, text := range []string{
+		"at 1m",
+		"at soon break-machine Grinder-0",
+		"at -1m break-machine Grinder-0",
+		"at 1m explode",
+		"at 1m break-machine",
+		"at 1m open-kiosk now",
+		"at 1m arrive many",
+		"at 1m arrive 5 Decaf during 1m",
+		"at 1m barista-break Barista-0 forever",
+		`at 1m sold-out "Decaf`,
+		"expect orders",
+		"expect happiness > 1",
+		"expect orders ~ 1",
+		"expect orders > lots",
+		"later 1m open-kiosk",
+	} {
+		_, err := ParseScenario(strings.NewReader("# first\n" + text))
+		if assert.Error(t, err, text) {
+			assert.True(t, strings.HasPrefix(err.Error(), "line 2:"), err.Error())
+		}
+	}
+}
+
+func TestExpectationCheck(t *testing.T) {
+	result := Result{Stats: models.Stats{OrdersServed: 10, TurnedAway: 2}}
+	assert.NoError(t, Expectation{Statistic: "orders", Op: ">=", Value: 10}.Check(result))
+	assert.NoError(t, Expectation{Statistic: "turned_away", Op: "!=", Value: 0}.Check(result))
+	err := Expectation{Line: 3, Statistic: "orders", Op: "<", Value: 10}.Check(result)
+	assert.EqualError(t, err, "line 3: expected orders < 10 but it was 10")
+}
+
+func TestPlayChecksTheShop(t *testing.T) {
+	for _, text := range []string{
+		"at 1m break-machine Grinder-9",
+		"at 1m sold-out Espresso",
+		"at 1m arrive 5 Espresso",
+		// the last of a kind of machine breaks for good
+		"at 1m break-machine Grinder-0\nat 2m break-machine Grinder-1",
+		"at 1m break-machine Drip-0",
+		"at 1m break-machine Drip-0\nat 2m repair-machine Grinder-0",
+		"at 1m break-machine Drip-0\nat 1m break-machine Grinder-0\nat 1m break-machine Grinder-1\nat 2m repair-machine Drip-0",
+		"at 1m break-machine Grinder-0\nat 2m repair-machine Grinder-0\nat 3m break-machine Grinder-1\nat 3m break-machine Grinder-0",
+	} {
+		scenario, err := ParseScenario(strings.NewReader(text))
+		assert.NoError(t, err)
+		shop, err := NewShop(scenarioConfig())
+		assert.NoError(t, err)
+		_, err = scenario.Play(shop)
+		assert.Error(t, err, text)
+		shop.Close()
+	}
+}
+
+func TestPlayRepairedBreakdowns(t *testing.T) {
+	for _, text := range []string{
+		"at 1m break-machine Grinder-0",
+		"at 1m break-machine Grinder-0\nat 2m break-machine Grinder-1\nat 3m repair-machine Grinder-1",
+		"at 2m repair-machine Drip-0\nat 1m break-machine Drip-0",
+		"at 1m break-machine Drip-0\nat 1m break-machine Grinder-0\nat 1m break-machine Grinder-1\nat 2m repair-machine Drip-0\nat 3m repair-machine Grinder-1",
+	} {
+		scenario, err := ParseScenario(strings.NewReader(text))
+		assert.NoError(t, err)
+		shop, err := NewShop(scenarioConfig())
+		assert.NoError(t, err)
+		playback, err := scenario.Play(shop)
+		assert.NoError(t, err, text)
+		playback.Stop()
+		shop.Close()
+	}
+}
+
+func TestPlayBursts(t *testing.T) {
+	scenario, err := ParseScenario(strings.NewReader("at 1m arrive 4 Decaf over 2m\nat 10m open-kiosk"))
+	assert.NoError(t, err)
+	shop, err := NewShop(scenarioConfig())
+	assert.NoError(t, err)
+	playback, err := scenario.Play(shop)
+	assert.NoError(t, err)
+	shop.Close()
+	assert.Empty(t, playback.Stop())
+
+	assert.Len(t, shop.Arrivals, 14)
+	for i, arrival := range shop.Arrivals[10:] {
+		assert.Equal(t, time.Duration(60+30*i)*models.SimSecond, arrival.At)
+		assert.Equal(t, "Decaf", arrival.Item.Name)
+	}
+}
+
+func TestPlayTimedFromTheCustomers(t *testing.T) {
+	scenario, err := ParseScenario(strings.NewReader(
+		"at 30s sold-out Decaf\nat 1m arrive 3 Decaf\nat 2m restock Decaf"))
+	assert.NoError(t, err)
+
+	synctest.Test(t, func(t *testing.T) {
+		config := scenarioConfig()
+		config.CustomerCount = 1
+		shop, err := NewShop(config)
+		assert.NoError(t, err)
+		playback, err := scenario.Play(shop)
+		assert.NoError(t, err)
+
+		// setting up takes a while, the decaf is still
+		// sold out when the burst comes in
+		time.Sleep(10 * time.Minute)
+		shop.SendCustomers()
+		shop.Close()
+		assert.Empty(t, playback.Stop())
+		assert.Equal(t, 3, shop.Stats().TurnedAway)
+	})
+}
+
+func TestScenarioFailures(t *testing.T) {
+	scenario, err := ParseScenario(strings.NewReader(
+		"at 0s remove-barista Nobody\nexpect orders == 10\nexpect orders > 10"))
+	assert.NoError(t, err)
+
+	result, failures, err := RunScenario(scenarioConfig(), scenario)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, result.Stats.OrdersServed)
+	assert.Len(t, failures, 2)
+	assert.EqualError(t, failures[0], "line 1: couldn't remove-barista Nobody")
+	assert.EqualError(t, failures[1], "line 3: expected orders > 10 but it was 10")
+}
+
+func TestScenarioFiles(t *testing.T) {
+	paths, err := filepath.Glob("testdata/scenarios/*.scenario")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Parallel()
+			scenario, err := LoadScenario(path)
+			assert.NoError(t, err)
+
+			_, failures, err := RunScenario(scenarioConfig(), scenario)
+			assert.NoError(t, err)
+			assert.Empty(t, failures)
+		})
+	}
+}
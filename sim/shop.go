@@ -0,0 +1,341 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/units"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	// a regular is about 8 fl oz and a large about 12
+	RegularSize units.Milliliters = 240
+	LargeSize   units.Milliliters = 355
+
+	// common ratio is 1 gram coffee per 16.5ml of water,
+	// a strong cup is closer to 1 gram per 12ml
+	RegularBrewingRatio units.Ratio = 16.5
+	StrongBrewingRatio  units.Ratio = 12
+)
+
+// the beans the shop carries
+var (
+	HouseBlend = models.BeanVariety{Origin: "Colombia", Roast: models.MediumRoast}
+	DarkRoast  = models.BeanVariety{Origin: "Sumatra", Roast: models.DarkRoast}
+	Decaf      = models.BeanVariety{Origin: "Colombia", Roast: models.MediumRoast, Decaf: true}
+)
+
+// Menu is everything the shop can make, given the brewers for it
+func Menu() models.Menu {
+	return models.Menu{
+		models.MenuItem{
+			Name:        "Regular",
+			Size:        RegularSize,
+			CoffeeRatio: RegularBrewingRatio,
+			Variety:     HouseBlend,
+			Methods:     []models.BrewMethod{models.Drip, models.PourOver, models.BatchDrip},
+		},
+		models.MenuItem{
+			Name:        "Regular Strong",
+			Size:        RegularSize,
+			CoffeeRatio: StrongBrewingRatio,
+			Variety:     DarkRoast,
+			Methods:     []models.BrewMethod{models.Drip, models.FrenchPress, models.AeroPress},
+		},
+		models.MenuItem{
+			Name:        "Large Regular",
+			Size:        LargeSize,
+			CoffeeRatio: RegularBrewingRatio,
+			Variety:     HouseBlend,
+			Methods:     []models.BrewMethod{models.Drip, models.PourOver, models.BatchDrip},
+		},
+		models.MenuItem{
+			Name:        "Large Strong",
+			Size:        LargeSize,
+			CoffeeRatio: StrongBrewingRatio,
+			Variety:     DarkRoast,
+			Methods:     []models.BrewMethod{models.Drip, models.FrenchPress},
+		},
+		models.MenuItem{
+			Name:        "Decaf",
+			Size:        RegularSize,
+			CoffeeRatio: RegularBrewingRatio,
+			Variety:     Decaf,
+			Methods:     []models.BrewMethod{models.Drip, models.AeroPress},
+		},
+		models.MenuItem{
+			Name:        "Pour Over",
+			Size:        RegularSize,
+			CoffeeRatio: RegularBrewingRatio,
+			Variety:     HouseBlend,
+			Methods:     []models.BrewMethod{models.PourOver},
+		},
+		models.MenuItem{
+			Name:        "French Press",
+			Size:        LargeSize,
+			CoffeeRatio: StrongBrewingRatio,
+			Variety:     DarkRoast,
+			Methods:     []models.BrewMethod{models.FrenchPress},
+		},
+	}
+}
+
+// Menu is what the config's brewers can make off the whole menu
+func (c Config) Menu() models.Menu {
+	methods := []models.BrewMethod{}
+	for method, count := range map[models.BrewMethod]int{
+		models.Drip:        c.BrewerCount,
+		models.PourOver:    c.PourOverCount,
+		models.FrenchPress: c.FrenchPressCount,
+		models.AeroPress:   c.AeroPressCount,
+		models.BatchDrip:   c.BatchBrewerCount,
+	} {
+		if count > 0 {
+			methods = append(methods, method)
+		}
+	}
+
+	return Menu().Brewable(methods...)
+}
+
+// Arrival is a customer ordering an item some time after the shop opens
+type Arrival struct {
+	At       time.Duration
+	Customer string
+	Item     models.MenuItem
+}
+
+// Shop is a coffee shop built from a config with the
+// menu it serves and the customers coming in
+type Shop struct {
+	models.CoffeeShop
+	Menu     models.Menu
+	Arrivals []Arrival
+	// Config is what the shop was built from, with the seed it used
+	Config Config
+
+	// starts are told when the customers start coming in
+	starts []func(time.Time)
+
+	// what the machines and baristas were built with,
+	// the queueing model works from them
+	grinderSpecs []grinderSpec
+	brewerSpecs  []brewerSpec
+	profiles     []models.BaristaProfile
+}
+
+// grinderSpec is the steady speed of a grinder
+type grinderSpec struct {
+	gramsPerSecond float64
+	decaf          bool
+}
+
+// brewerSpec is the steady brew time of a brewer, a fixed time
+// plus the water at its rate when it has one
+type brewerSpec struct {
+	method         models.BrewMethod
+	fixed          time.Duration
+	waterPerSecond float64
+	cups           int
+}
+
+// NewShop builds the shop the config describes, the options are added
+// to the ones from the config.  The config's seed picks the machine
+// speeds, the customers' orders and when they arrive, so a seed always
+// builds the same shop.  The grinders, brewers, baristas and customers
+// each draw from their own stream off the seed, so changing one of them
+// leaves the rest as they were.  Shops built with the same seed share
+// their random numbers and can be compared run for run.
+func NewShop(c Config, opts ...models.ShopOption) (*Shop, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	streams := rand.New(rand.NewSource(c.Seed))
+	grinderRand := rand.New(rand.NewSource(streams.Int63()))
+	brewerRand := rand.New(rand.NewSource(streams.Int63()))
+	baristaSeed := streams.Int63()
+	customerRand := rand.New(rand.NewSource(streams.Int63()))
+
+	// machines have a steady time for their work that can be
+	// spread out to be more like the real thing
+	timing := func(rng *rand.Rand, steady models.DurationModel) models.DurationModel {
+		if c.TimingSpread <= 0 {
+			return steady
+		}
+
+		return models.NewLognormalDuration(steady, c.TimingSpread, rng.Int63())
+	}
+	warmUp := models.WarmUp{
+		Time:      time.Duration(c.WarmUpSeconds) * models.SimSecond,
+		IdleAfter: time.Duration(c.IdleSeconds) * models.SimSecond,
+	}
+
+	// only offer what the brewers can make
+	menu := c.Menu()
+	if len(menu) == 0 {
+		return nil, errors.New("no brewers to make anything on the menu")
+	}
+
+	// Create pool of grinders.  They grind in grams per second
+	grinderSpecs := []grinderSpec{}
+	grinders := models.NewGrinderPool()
+	for i := 0; i < c.GrinderCount+c.DecafGrinderCount; i++ {
+		// create a grinder with 1 to 10 grams per second speed
+		gramsPerSecond := units.GramsPerSecond(1 + grinderRand.Intn(10))
+		opts := []models.GrinderOption{
+			models.WithGrindModel(timing(grinderRand, models.NewRateDuration(float64(gramsPerSecond)))),
+			models.WithGrinderWarmUp(warmUp),
+		}
+		if i >= c.GrinderCount {
+			opts = append(opts, models.WithDedicatedVariety(Decaf))
+		}
+		grinderSpecs = append(grinderSpecs, grinderSpec{gramsPerSecond: float64(gramsPerSecond), decaf: i >= c.GrinderCount})
+		grinders.AddGrinder(models.NewGrinder(gramsPerSecond, opts...))
+	}
+
+	// Create pool of brewers.  They brew in milliliters per second
+	brewerSpecs := []brewerSpec{}
+	brewers := models.NewBrewerPool()
+	for i := 0; i < c.BrewerCount; i++ {
+		// create brewer with 1 to 4 ml per second, a regular takes 1 to 4
+		// minutes which gives a good extraction
+		waterPerSecond := units.MillilitersPerSecond(1 + brewerRand.Intn(4))
+		brewers.AddBrewer(models.NewBrewer(waterPerSecond,
+			models.WithCups(c.BrewerCups),
+			models.WithBrewModel(timing(brewerRand, models.NewRateDuration(float64(waterPerSecond)))),
+			models.WithBrewerWarmUp(warmUp)))
+		brewerSpecs = append(brewerSpecs, brewerSpec{method: models.Drip, waterPerSecond: float64(waterPerSecond), cups: c.BrewerCups})
+	}
+	for i := 0; i < c.PourOverCount; i++ {
+		// 30 second bloom then pour at 1 to 4 ml per second
+		waterPerSecond := units.MillilitersPerSecond(1 + brewerRand.Intn(4))
+		bloom := 30 * models.SimSecond
+		brewers.AddBrewer(models.NewPourOverBrewer(30, waterPerSecond,
+			models.WithBrewModel(timing(brewerRand, models.NewFixedPlusRateDuration(bloom, float64(waterPerSecond)))),
+			models.WithBrewerWarmUp(warmUp)))
+		brewerSpecs = append(brewerSpecs, brewerSpec{method: models.PourOver, fixed: bloom, waterPerSecond: float64(waterPerSecond), cups: 1})
+	}
+	for i := 0; i < c.FrenchPressCount; i++ {
+		// 4 minute steep
+		brewers.AddBrewer(models.NewFrenchPressBrewer(240,
+			models.WithBrewModel(timing(brewerRand, models.NewFixedDuration(240*models.SimSecond))),
+			models.WithBrewerWarmUp(warmUp)))
+		brewerSpecs = append(brewerSpecs, brewerSpec{method: models.FrenchPress, fixed: 240 * models.SimSecond, cups: 4})
+	}
+	for i := 0; i < c.AeroPressCount; i++ {
+		// 1 minute steep and 30 second plunge
+		brewers.AddBrewer(models.NewAeroPressBrewer(60, 30,
+			models.WithBrewModel(timing(brewerRand, models.NewFixedDuration(90*models.SimSecond))),
+			models.WithBrewerWarmUp(warmUp)))
+		brewerSpecs = append(brewerSpecs, brewerSpec{method: models.AeroPress, fixed: 90 * models.SimSecond, cups: 1})
+	}
+
+	shopOptions := []models.ShopOption{}
+	for i := 0; i < c.BatchBrewerCount; i++ {
+		// batch brewers run 10 to 20 ml per second to fill a carafe
+		waterPerSecond := units.MillilitersPerSecond(10 + brewerRand.Intn(11))
+		brewers.AddBrewer(models.NewBatchBrewer(waterPerSecond,
+			models.WithBrewModel(timing(brewerRand, models.NewRateDuration(float64(waterPerSecond)))),
+			models.WithBrewerWarmUp(warmUp)))
+		brewerSpecs = append(brewerSpecs, brewerSpec{method: models.BatchDrip, waterPerSecond: float64(waterPerSecond), cups: 1})
+	}
+	if c.BatchBrewerCount > 0 {
+		holdTime := time.Duration(c.CarafeHoldSeconds) * models.SimSecond
+		shopOptions = append(shopOptions, models.WithBatchBrewing(holdTime, c.CarafeCups))
+	}
+
+	// the trainees are the last of the baristas, slower and more
+	// likely to make mistakes at the stations they know
+	profiles := []models.BaristaProfile{}
+	for i := 0; i < c.BaristaCount; i++ {
+		profile := models.DefaultBaristaProfile(fmt.Sprintf("Barista-%d", i))
+		profile.Speed = c.BaristaSpeed
+		profile.ErrorRate = c.BaristaErrorRate
+		if i >= c.BaristaCount-c.TraineeCount {
+			profile.Name = fmt.Sprintf("Trainee-%d", i)
+			profile.Speed = 0.5
+			profile.ErrorRate = 0.1
+			profile.Stations = []models.Station{models.GrindStation, models.DripStation}
+		}
+		profiles = append(profiles, profile)
+	}
+	shopOptions = append(shopOptions,
+		models.WithBaristaProfiles(profiles...),
+		models.WithSeed(baristaSeed))
+
+	// with open hours the baristas work shifts staggered across
+	// the day with a break in the middle of each
+	day := time.Duration(c.OpenHours * 3600 * float64(models.SimSecond))
+	if c.OpenHours > 0 {
+		shift := time.Duration(c.ShiftHours * 3600 * float64(models.SimSecond))
+		if shift > day {
+			shift = day
+		}
+		breakLength := time.Duration(c.BreakMinutes*60) * models.SimSecond
+		shifts := []models.Shift{}
+		for i := 0; i < c.BaristaCount; i++ {
+			start := time.Duration(0)
+			if c.BaristaCount > 1 {
+				start = (day - shift) * time.Duration(i) / time.Duration(c.BaristaCount-1)
+			}
+			s := models.Shift{Start: start, End: start + shift}
+			if breakLength > 0 {
+				s.Breaks = []models.Break{{Start: start + (shift-breakLength)/2, Length: breakLength}}
+			}
+			shifts = append(shifts, s)
+		}
+		shopOptions = append(shopOptions, models.WithShifts(shifts...))
+	}
+
+	// the autoscaler adds baristas past the ones on staff when it's busy
+	if c.AutoscaleMax > c.BaristaCount {
+		shopOptions = append(shopOptions, models.WithAutoscaler(models.AutoscalePolicy{
+			MinBaristas: c.BaristaCount,
+			MaxBaristas: c.AutoscaleMax,
+			MaxQueue:    c.AutoscaleQueue,
+			MaxWait:     time.Duration(c.AutoscaleWaitSeconds) * models.SimSecond,
+			Profile: models.BaristaProfile{
+				Speed:     c.BaristaSpeed,
+				ErrorRate: c.BaristaErrorRate,
+			},
+		}))
+	}
+
+	shopOptions = append(shopOptions, opts...)
+	result := &Shop{
+		CoffeeShop: models.NewCoffeeShop(menu, c.KioskCount, c.BaristaCount, c.BaristaOrderCount, grinders, brewers, shopOptions...),
+		Menu:       menu,
+		Config:     c,
+
+		grinderSpecs: grinderSpecs,
+		brewerSpecs:  brewerSpecs,
+		profiles:     profiles,
+	}
+	if len(c.Workload) > 0 {
+		for _, arrival := range c.Workload {
+			if _, found := menu.Item(arrival.Item.Name); !found {
+				result.Close()
+				return nil, fmt.Errorf("%s orders %s and there are no brewers to make it", arrival.Customer, arrival.Item.Name)
+			}
+		}
+		result.Arrivals = append([]Arrival{}, c.Workload...)
+		return result, nil
+	}
+	for i := 0; i < c.CustomerCount; i++ {
+		// a random coffee off the menu some time in the day
+		item := menu[customerRand.Intn(len(menu))]
+		at := time.Duration(customerRand.Int63n(int64(day) + 1))
+		result.Arrivals = append(result.Arrivals, Arrival{
+			At:       at,
+			Customer: fmt.Sprintf("Customer-%d", i),
+			Item:     item,
+		})
+	}
+
+	return result, nil
+}
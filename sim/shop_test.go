@@ -0,0 +1,101 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigMenu(t *testing.T) {
+	config := DefaultConfig()
+	assert.Len(t, config.Menu(), 5)
+
+	config.BrewerCount = 0
+	config.FrenchPressCount = 1
+	names := []string{}
+	for _, item := range config.Menu() {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"Regular Strong", "Large Strong", "French Press"}, names)
+}
+
+func TestNewShopIsSeeded(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 5
+	config.OpenHours = 1
+	config.Seed = 42
+
+	first, err := NewShop(config)
+	assert.NoError(t, err)
+	first.Close()
+	second, err := NewShop(config)
+	assert.NoError(t, err)
+	second.Close()
+
+	assert.Len(t, first.Arrivals, 5)
+	assert.Equal(t, first.Arrivals, second.Arrivals)
+	assert.Equal(t, config.Menu(), first.Menu)
+}
+
+func TestNewShopErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.GrinderCount = 0
+	_, err := NewShop(config)
+	assert.Error(t, err)
+
+	// a shop that can't finish an order fails instead of hanging
+	for _, broken := range []func(*Config){
+		func(c *Config) { c.BrewerCount = 0 },
+		func(c *Config) { c.BaristaCount = 0 },
+		func(c *Config) { c.BaristaOrderCount = 0 },
+		func(c *Config) { c.BrewerCups = 0 },
+		func(c *Config) { c.BrewerCount, c.BatchBrewerCount, c.CarafeCups = 0, 1, 0 },
+	} {
+		config = DefaultConfig()
+		broken(&config)
+		_, err = Run(config)
+		assert.Error(t, err, "%+v", config)
+	}
+
+	config = DefaultConfig()
+	config.BrewerCount = 0
+	config.FrenchPressCount = 1
+	shop, err := NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+}
+
+func TestRun(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 3
+	config.BaristaCount = 2
+
+	result, err := Run(config, models.WithSeed(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Stats.OrdersServed)
+	assert.Len(t, result.Stats.Waits, 3)
+	assert.Greater(t, result.Throughput(), 0.0)
+	assert.Greater(t, result.RunTime, result.Stats.MaxWait/2)
+}
+
+func TestNewShopCommonRandomNumbers(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 5
+	config.OpenHours = 1
+	config.Seed = 42
+	first, err := NewShop(config)
+	assert.NoError(t, err)
+	first.Close()
+
+	// more grinders and baristas don't change the customers or brewers
+	config.GrinderCount = 3
+	config.BaristaCount = 2
+	second, err := NewShop(config)
+	assert.NoError(t, err)
+	second.Close()
+
+	assert.Equal(t, first.Arrivals, second.Arrivals)
+	assert.Equal(t, first.brewerSpecs, second.brewerSpecs)
+	assert.Equal(t, first.grinderSpecs, second.grinderSpecs[:1])
+}
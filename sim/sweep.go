@@ -0,0 +1,283 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dimension is a setting a sweep varies and the values it takes
+type Dimension struct {
+	Name   string
+	Values []string
+}
+
+// ParseDimension reads a setting and its values, a range of
+// whole numbers like barista-count=1..4 or a list like
+// barista-speed=0.5,1,2
+func ParseDimension(arg string) (Dimension, error) {
+	name, values, found := strings.Cut(arg, "=")
+	if !found || name == "" || values == "" {
+		return Dimension{}, fmt.Errorf("%q isn't a setting=values", arg)
+	}
+
+	result := Dimension{Name: name}
+	if low, high, isRange := strings.Cut(values, ".."); isRange {
+		from, err := strconv.Atoi(low)
+		if err != nil {
+			return Dimension{}, fmt.Errorf("%s range: %w", name, err)
+		}
+		to, err := strconv.Atoi(high)
+		if err != nil {
+			return Dimension{}, fmt.Errorf("%s range: %w", name, err)
+		}
+		if to < from {
+			return Dimension{}, fmt.Errorf("%s range %s is empty", name, values)
+		}
+		for i := from; i <= to; i++ {
+			result.Values = append(result.Values, strconv.Itoa(i))
+		}
+		return result, nil
+	}
+
+	result.Values = strings.Split(values, ",")
+	return result, nil
+}
+
+// Combinations is every combination of the dimensions' values, the
+// values in each are in the order of the dimensions and the last
+// dimension changes fastest
+func Combinations(dimensions []Dimension) [][]string {
+	result := [][]string{{}}
+	for _, dimension := range dimensions {
+		next := [][]string{}
+		for _, combination := range result {
+			for _, value := range dimension.Values {
+				next = append(next, append(append([]string{}, combination...), value))
+			}
+		}
+		result = next
+	}
+
+	return result
+}
+
+// Point is a combination of settings in a sweep and the config they make
+type Point struct {
+	Settings []string
+	Config   Config
+}
+
+// Row is how a point in a sweep did over all its runs, the wait
+// percentiles are over the orders from every run
+type Row struct {
+	Settings     []string
+	Replications int
+	// Orders served in all the runs
+	Orders      int
+	AverageWait time.Duration
+	P50         time.Duration
+	P90         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+	MaxWait     time.Duration
+	// Throughput is the average orders served per simulated hour
+	Throughput float64
+}
+
+// Sweep runs each point's config with the seeds from its seed up, so
+// every point gets the same customers in each replication.  Parallel
+// runs share the CPU, too many slow the shops down.
+type Sweep struct {
+	Points       []Point
+	Replications int
+	Parallel     int
+	// Progress is told about each run as it finishes, it can be nil
+	Progress func(done int, total int, point Point, err error)
+}
+
+type sweepRun struct {
+	point       int
+	replication int
+}
+
+// Run runs every point and returns their rows in the same order
+func (s Sweep) Run() ([]Row, error) {
+	results, err := runPoints(s.Points, s.Replications, s.Parallel, s.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []Row{}
+	for i, point := range s.Points {
+		rows = append(rows, newRow(point.Settings, results[i]))
+	}
+
+	return rows, nil
+}
+
+// runPoints runs each point's config with the seeds from its seed up
+// and returns every point's results in the same order.  The points
+// without a seed share one from the clock, it's picked before the
+// replications count up from it so their runs still pair up.
+func runPoints(points []Point, replications int, parallel int, progress func(int, int, Point, error)) ([][]Result, error) {
+	replications = max(replications, 1)
+	seed := time.Now().UnixNano()
+	points = slices.Clone(points)
+	for i := range points {
+		if points[i].Config.Seed == 0 {
+			points[i].Config.Seed = seed
+		}
+	}
+	results := make([][]Result, len(points))
+	for i := range results {
+		results[i] = make([]Result, replications)
+	}
+
+	runs := make(chan sweepRun)
+	lock := &sync.Mutex{}
+	done := 0
+	var firstErr error
+	workers := sync.WaitGroup{}
+	for w := 0; w < max(parallel, 1); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for run := range runs {
+				point := points[run.point]
+				config := point.Config
+				config.Seed += int64(run.replication)
+				result, err := Run(config)
+
+				lock.Lock()
+				results[run.point][run.replication] = result
+				done++
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if progress != nil {
+					progress(done, len(points)*replications, point, err)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	for point := range points {
+		for replication := 0; replication < replications; replication++ {
+			runs <- sweepRun{point: point, replication: replication}
+		}
+	}
+	close(runs)
+	workers.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return results, nil
+}
+
+func newRow(settings []string, results []Result) Row {
+	row := Row{Settings: settings, Replications: len(results)}
+	waits := []time.Duration{}
+	totalWait := time.Duration(0)
+	throughput := 0.0
+	for _, result := range results {
+		row.Orders += result.Stats.OrdersServed
+		totalWait += result.Stats.TotalWait
+		waits = append(waits, result.Stats.Waits...)
+		throughput += result.Throughput()
+	}
+	if row.Orders > 0 {
+		row.AverageWait = totalWait / time.Duration(row.Orders)
+	}
+	row.P50 = models.Percentile(waits, 50)
+	row.P90 = models.Percentile(waits, 90)
+	row.P95 = models.Percentile(waits, 95)
+	row.P99 = models.Percentile(waits, 99)
+	row.MaxWait = models.Percentile(waits, 100)
+	if len(results) > 0 {
+		row.Throughput = throughput / float64(len(results))
+	}
+
+	return row
+}
+
+// the columns after the settings, times are in simulated seconds
+var rowColumns = []string{
+	"replications",
+	"orders",
+	"throughput_per_hour",
+	"avg_wait_seconds",
+	"p50_seconds",
+	"p90_seconds",
+	"p95_seconds",
+	"p99_seconds",
+	"max_wait_seconds",
+}
+
+func (r Row) values() []float64 {
+	return []float64{
+		float64(r.Replications),
+		float64(r.Orders),
+		r.Throughput,
+		models.SimSeconds(r.AverageWait),
+		models.SimSeconds(r.P50),
+		models.SimSeconds(r.P90),
+		models.SimSeconds(r.P95),
+		models.SimSeconds(r.P99),
+		models.SimSeconds(r.MaxWait),
+	}
+}
+
+// WriteCSV writes the rows with a column for each dimension
+func WriteCSV(w io.Writer, dimensions []Dimension, rows []Row) error {
+	out := csv.NewWriter(w)
+	header := []string{}
+	for _, dimension := range dimensions {
+		header = append(header, dimension.Name)
+	}
+	if err := out.Write(append(header, rowColumns...)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := append([]string{}, row.Settings...)
+		for _, value := range row.values() {
+			record = append(record, strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64))
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// WriteJSON writes the rows as a JSON array, each with its settings
+func WriteJSON(w io.Writer, dimensions []Dimension, rows []Row) error {
+	records := []map[string]any{}
+	for _, row := range rows {
+		settings := map[string]string{}
+		for i, dimension := range dimensions {
+			settings[dimension.Name] = row.Settings[i]
+		}
+		record := map[string]any{"settings": settings}
+		for i, value := range row.values() {
+			record[rowColumns[i]] = math.Round(value*1000) / 1000
+		}
+		records = append(records, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
@@ -0,0 +1,123 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDimension(t *testing.T) {
+	dimension, err := ParseDimension("barista-count=1..4")
+	assert.NoError(t, err)
+	assert.Equal(t, Dimension{Name: "barista-count", Values: []string{"1", "2", "3", "4"}}, dimension)
+
+	dimension, err = ParseDimension("barista-speed=0.5,1,2")
+	assert.NoError(t, err)
+	assert.Equal(t, Dimension{Name: "barista-speed", Values: []string{"0.5", "1", "2"}}, dimension)
+
+	for _, bad := range []string{"barista-count", "=1..2", "barista-count=", "barista-count=3..1", "barista-count=a..2"} {
+		_, err := ParseDimension(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	combinations := Combinations([]Dimension{
+		{Name: "barista-count", Values: []string{"1", "2"}},
+		{Name: "grinder-count", Values: []string{"1", "2", "3"}},
+	})
+
+	assert.Equal(t, [][]string{
+		{"1", "1"}, {"1", "2"}, {"1", "3"},
+		{"2", "1"}, {"2", "2"}, {"2", "3"},
+	}, combinations)
+}
+
+func TestNewRow(t *testing.T) {
+	hour := 3600 * models.SimSecond
+	row := newRow([]string{"2"}, []Result{
+		{Stats: models.Stats{OrdersServed: 2, TotalWait: 30 * models.SimSecond, Waits: []time.Duration{10 * models.SimSecond, 20 * models.SimSecond}, OpenTime: hour}},
+		{Stats: models.Stats{OrdersServed: 2, TotalWait: 70 * models.SimSecond, Waits: []time.Duration{30 * models.SimSecond, 40 * models.SimSecond}, OpenTime: hour / 2}},
+	})
+
+	assert.Equal(t, 2, row.Replications)
+	assert.Equal(t, 4, row.Orders)
+	assert.Equal(t, 25*models.SimSecond, row.AverageWait)
+	assert.Equal(t, 20*models.SimSecond, row.P50)
+	assert.Equal(t, 40*models.SimSecond, row.P95)
+	assert.Equal(t, 40*models.SimSecond, row.MaxWait)
+	// 2 and 4 orders an hour
+	assert.InDelta(t, 3, row.Throughput, 0.001)
+}
+
+func TestWriteTables(t *testing.T) {
+	dimensions := []Dimension{{Name: "barista-count", Values: []string{"1"}}}
+	rows := []Row{{
+		Settings:     []string{"1"},
+		Replications: 2,
+		Orders:       10,
+		AverageWait:  1500 * time.Microsecond,
+		P95:          2 * models.SimSecond,
+		Throughput:   12.34567,
+	}}
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, WriteCSV(out, dimensions, rows))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "barista-count,replications,orders,throughput_per_hour,avg_wait_seconds,p50_seconds,p90_seconds,p95_seconds,p99_seconds,max_wait_seconds", lines[0])
+	assert.Equal(t, "1,2,10,12.346,1.5,0,0,2,0,0", lines[1])
+
+	out.Reset()
+	assert.NoError(t, WriteJSON(out, dimensions, rows))
+	records := []map[string]any{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	assert.Len(t, records, 1)
+	assert.Equal(t, map[string]any{"barista-count": "1"}, records[0]["settings"])
+	assert.Equal(t, 1.5, records[0]["avg_wait_seconds"])
+	assert.Equal(t, 12.346, records[0]["throughput_per_hour"])
+}
+
+func TestSweep(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 2
+	config.Seed = 1
+	points := []Point{}
+	for _, baristas := range []int{1, 2} {
+		config.BaristaCount = baristas
+		points = append(points, Point{Settings: []string{strconv.Itoa(baristas)}, Config: config})
+	}
+
+	progress := 0
+	rows, err := Sweep{
+		Points:       points,
+		Replications: 2,
+		Parallel:     4,
+		Progress: func(done int, total int, point Point, err error) {
+			progress++
+			assert.Equal(t, 4, total)
+			assert.NoError(t, err)
+		},
+	}.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, progress)
+	assert.Len(t, rows, 2)
+	for i, row := range rows {
+		assert.Equal(t, points[i].Settings, row.Settings)
+		assert.Equal(t, 2, row.Replications)
+		assert.Equal(t, 4, row.Orders)
+		assert.Greater(t, row.P95, time.Duration(0))
+		assert.GreaterOrEqual(t, row.MaxWait, row.P95)
+		assert.Greater(t, row.Throughput, 0.0)
+	}
+
+	// a bad config stops the sweep
+	config.GrinderCount = 0
+	_, err = Sweep{Points: []Point{{Config: config}}}.Run()
+	assert.Error(t, err)
+}
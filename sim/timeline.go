@@ -0,0 +1,109 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timeline is a tracer that keeps every span of every order so the run
+// can be written out order by order, the times are in simulated seconds
+// from when the timeline was made
+type Timeline struct {
+	lock  *sync.Mutex
+	start time.Time
+	spans []models.Span
+}
+
+// NewTimeline starts the timeline's clock now, make it
+// with the shop so the times are from when it opened
+func NewTimeline() *Timeline {
+	return &Timeline{
+		lock:  &sync.Mutex{},
+		start: time.Now(),
+	}
+}
+
+func (tl *Timeline) Export(span models.Span) {
+	tl.lock.Lock()
+	defer tl.lock.Unlock()
+
+	tl.spans = append(tl.spans, span)
+}
+
+// Write writes each order in the order the customers came in with
+// its steps under it in the order they started.  Order and span IDs
+// are left out, they're different every run.
+//
+//	12.500 Customer-3 Regular complete 190.250
+//	  12.500 kiosk_wait 0.000
+//	  12.500 grinder_wait 0.000 barista=Barista-0 machine=Grinder-0
+func (tl *Timeline) Write(w io.Writer) error {
+	tl.lock.Lock()
+	defer tl.lock.Unlock()
+
+	orders := []models.Span{}
+	steps := map[int64][]models.Span{}
+	for _, span := range tl.spans {
+		if span.ParentID == 0 {
+			orders = append(orders, span)
+		} else {
+			steps[span.TraceID] = append(steps[span.TraceID], span)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		if !orders[i].Start.Equal(orders[j].Start) {
+			return orders[i].Start.Before(orders[j].Start)
+		}
+		return orders[i].Attributes["customer"] < orders[j].Attributes["customer"]
+	})
+
+	for _, order := range orders {
+		_, err := fmt.Fprintf(w, "%.3f %s %s %s %.3f\n", tl.seconds(order.Start),
+			order.Attributes["customer"], order.Attributes["item"], order.Attributes["status"],
+			models.SimSeconds(order.Duration()))
+		if err != nil {
+			return err
+		}
+
+		spans := steps[order.TraceID]
+		sort.SliceStable(spans, func(i, j int) bool {
+			if !spans[i].Start.Equal(spans[j].Start) {
+				return spans[i].Start.Before(spans[j].Start)
+			}
+			return spans[i].SpanID < spans[j].SpanID
+		})
+		for _, span := range spans {
+			_, err := fmt.Fprintf(w, "  %.3f %s %.3f%s\n", tl.seconds(span.Start), span.Name,
+				models.SimSeconds(span.Duration()), attributes(span.Attributes))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (tl *Timeline) seconds(at time.Time) float64 {
+	return models.SimSeconds(at.Sub(tl.start))
+}
+
+// attributes are the span's name=value pairs sorted by name
+func attributes(values map[string]string) string {
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(result, " %s=%s", name, values[name])
+	}
+	return result.String()
+}
@@ -0,0 +1,253 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"blreynolds4/coffeeshop/units"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the workload file formats
+const (
+	WorkloadCSV   = "csv"
+	WorkloadJSONL = "jsonl"
+)
+
+var workloadColumns = []string{"at_seconds", "customer", "item", "size_ml", "ratio"}
+
+// WorkloadRecord is a customer in a workload file.  At is the simulated
+// seconds after the shop opens they arrive.  The size and ratio modify
+// the menu's item, 0 leaves it as the menu has it.
+type WorkloadRecord struct {
+	At       float64 `json:"at_seconds"`
+	Customer string  `json:"customer"`
+	Item     string  `json:"item"`
+	SizeML   float64 `json:"size_ml,omitempty"`
+	Ratio    float64 `json:"ratio,omitempty"`
+}
+
+// WorkloadFormat is the format for the file's extension,
+// .jsonl is JSON lines and .csv is CSV
+func WorkloadFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return WorkloadCSV, nil
+	case ".jsonl":
+		return WorkloadJSONL, nil
+	}
+
+	return "", fmt.Errorf("%s isn't a .csv or .jsonl workload", path)
+}
+
+// LoadWorkload reads the customers in the workload file,
+// the items they order have to be on the menu
+func LoadWorkload(path string, menu models.Menu) ([]Arrival, error) {
+	format, err := WorkloadFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	arrivals, err := ReadWorkload(file, format, menu)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return arrivals, nil
+}
+
+// SaveWorkload writes the customers to the workload file
+func SaveWorkload(path string, arrivals []Arrival) error {
+	format, err := WorkloadFormat(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteWorkload(file, format, arrivals); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WorkloadHours is the hours from opening to the last customer's
+// arrival, the open hours the workload's customers are spread over
+func WorkloadHours(arrivals []Arrival) float64 {
+	last := time.Duration(0)
+	for _, arrival := range arrivals {
+		last = max(last, arrival.At)
+	}
+
+	return float64(last) / float64(models.SimHour)
+}
+
+// ReadWorkload reads the customers in the format, sorted by when they arrive
+func ReadWorkload(r io.Reader, format string, menu models.Menu) ([]Arrival, error) {
+	records := []WorkloadRecord{}
+	switch format {
+	case WorkloadCSV:
+		in := csv.NewReader(r)
+		in.FieldsPerRecord = len(workloadColumns)
+		rows, err := in.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(workloadColumns, ",") {
+			return nil, fmt.Errorf("the header has to be %s", strings.Join(workloadColumns, ","))
+		}
+		for i, row := range rows[1:] {
+			record, err := parseWorkloadRow(row)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+2, err)
+			}
+			records = append(records, record)
+		}
+	case WorkloadJSONL:
+		scanner := bufio.NewScanner(r)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			record := WorkloadRecord{}
+			decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&record); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			records = append(records, record)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("no %s workload format", format)
+	}
+
+	arrivals := []Arrival{}
+	for i, record := range records {
+		arrival, err := record.arrival(menu)
+		if err != nil {
+			return nil, fmt.Errorf("customer %d: %w", i+1, err)
+		}
+		arrivals = append(arrivals, arrival)
+	}
+	sort.SliceStable(arrivals, func(i, j int) bool {
+		return arrivals[i].At < arrivals[j].At
+	})
+
+	return arrivals, nil
+}
+
+func parseWorkloadRow(row []string) (WorkloadRecord, error) {
+	record := WorkloadRecord{Customer: row[1], Item: row[2]}
+	numbers := []*float64{&record.At, nil, nil, &record.SizeML, &record.Ratio}
+	for i, number := range numbers {
+		if number == nil || row[i] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(row[i], 64)
+		if err != nil {
+			return WorkloadRecord{}, fmt.Errorf("%s %q isn't a number", workloadColumns[i], row[i])
+		}
+		*number = value
+	}
+
+	return record, nil
+}
+
+// arrival is the customer ordering the menu's item with the modifiers
+func (wr WorkloadRecord) arrival(menu models.Menu) (Arrival, error) {
+	item, found := menu.Item(wr.Item)
+	if !found {
+		return Arrival{}, fmt.Errorf("%s isn't on the menu", wr.Item)
+	}
+	if wr.Customer == "" {
+		return Arrival{}, errors.New("the customer needs a name")
+	}
+	if wr.At < 0 || wr.SizeML < 0 || wr.Ratio < 0 {
+		return Arrival{}, errors.New("the time, size and ratio can't be negative")
+	}
+	if wr.SizeML > 0 {
+		item.Size = units.Milliliters(wr.SizeML)
+	}
+	if wr.Ratio > 0 {
+		item.CoffeeRatio = units.Ratio(wr.Ratio)
+	}
+
+	return Arrival{
+		At:       time.Duration(math.Round(wr.At * float64(models.SimSecond))),
+		Customer: wr.Customer,
+		Item:     item,
+	}, nil
+}
+
+// WriteWorkload writes the customers in the format in the order they
+// arrive, with the size and ratio of what they ordered so replaying
+// them orders the same coffee even when the menu has changed
+func WriteWorkload(w io.Writer, format string, arrivals []Arrival) error {
+	arrivals = append([]Arrival{}, arrivals...)
+	sort.SliceStable(arrivals, func(i, j int) bool {
+		return arrivals[i].At < arrivals[j].At
+	})
+
+	switch format {
+	case WorkloadCSV:
+		out := csv.NewWriter(w)
+		if err := out.Write(workloadColumns); err != nil {
+			return err
+		}
+		for _, arrival := range arrivals {
+			record := newWorkloadRecord(arrival)
+			err := out.Write([]string{
+				strconv.FormatFloat(record.At, 'f', -1, 64),
+				record.Customer,
+				record.Item,
+				strconv.FormatFloat(record.SizeML, 'f', -1, 64),
+				strconv.FormatFloat(record.Ratio, 'f', -1, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return out.Error()
+	case WorkloadJSONL:
+		encoder := json.NewEncoder(w)
+		for _, arrival := range arrivals {
+			if err := encoder.Encode(newWorkloadRecord(arrival)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no %s workload format", format)
+}
+
+func newWorkloadRecord(arrival Arrival) WorkloadRecord {
+	return WorkloadRecord{
+		At:       models.SimSeconds(arrival.At),
+		Customer: arrival.Customer,
+		Item:     arrival.Item.Name,
+		SizeML:   float64(arrival.Item.Size),
+		Ratio:    float64(arrival.Item.CoffeeRatio),
+	}
+}
@@ -0,0 +1,115 @@
+package sim
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadFormat(t *testing.T) {
+	format, err := WorkloadFormat("day.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, WorkloadCSV, format)
+	format, err = WorkloadFormat("logs/day.jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, WorkloadJSONL, format)
+	_, err = WorkloadFormat("day.txt")
+	assert.Error(t, err)
+}
+
+func TestReadWorkload(t *testing.T) {
+	regular, _ := Menu().Item("Regular")
+	strong := regular
+	strong.Size = 300
+	strong.CoffeeRatio = 12
+	expected := []Arrival{
+		{At: 1500 * time.Microsecond, Customer: "Ann", Item: strong},
+		{At: 10 * models.SimSecond, Customer: "Bob", Item: regular},
+	}
+
+	arrivals, err := ReadWorkload(strings.NewReader(
+		"at_seconds,customer,item,size_ml,ratio\n"+
+			"10,Bob,Regular,,\n"+
+			"1.5,Ann,Regular,300,12\n"), WorkloadCSV, Menu())
+	assert.NoError(t, err)
+	assert.Equal(t, expected, arrivals)
+
+	arrivals, err = ReadWorkload(strings.NewReader(
+		`{"at_seconds":10,"customer":"Bob","item":"Regular"}`+"\n\n"+
+			`{"at_seconds":1.5,"customer":"Ann","item":"Regular","size_ml":300,"ratio":12}`+"\n"), WorkloadJSONL, Menu())
+	assert.NoError(t, err)
+	assert.Equal(t, expected, arrivals)
+	assert.InDelta(t, 10.0/3600, WorkloadHours(arrivals), 1e-12)
+}
+
+func TestReadWorkloadErrors(t *testing.T) {
+	for _, test := range []struct {
+		format string
+		text   string
+	}{
+		{WorkloadCSV, ""},
+		{WorkloadCSV, "when,who,what,size,ratio\n"},
+		{WorkloadCSV, "at_seconds,customer,item,size_ml,ratio\nsoon,Bob,Regular,,\n"},
+		{WorkloadCSV, "at_seconds,customer,item,size_ml,ratio\n1,Bob,Regular\n"},
+		{WorkloadCSV, "at_seconds,customer,item,size_ml,ratio\n1,Bob,Espresso,,\n"},
+		{WorkloadCSV, "at_seconds,customer,item,size_ml,ratio\n1,,Regular,,\n"},
+		{WorkloadCSV, "at_seconds,customer,item,size_ml,ratio\n-1,Bob,Regular,,\n"},
+		{WorkloadJSONL, `{"at_seconds":1,"customer":"Bob","item":"Regular","milk":"oat"}`},
+		{WorkloadJSONL, `{"at_seconds":1,`},
+		{"xml", ""},
+	} {
+		_, err := ReadWorkload(strings.NewReader(test.text), test.format, Menu())
+		assert.Error(t, err, test.text)
+	}
+}
+
+func TestWorkloadRoundTrip(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomerCount = 20
+	config.OpenHours = 1
+	config.Seed = 9
+	shop, err := NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+
+	for _, format := range []string{WorkloadCSV, WorkloadJSONL} {
+		out := &bytes.Buffer{}
+		assert.NoError(t, WriteWorkload(out, format, shop.Arrivals))
+		arrivals, err := ReadWorkload(out, format, Menu())
+		assert.NoError(t, err)
+		assert.Len(t, arrivals, 20)
+		for _, arrival := range arrivals {
+			assert.Contains(t, shop.Arrivals, arrival, format)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "day.csv")
+	assert.NoError(t, SaveWorkload(path, shop.Arrivals))
+	arrivals, err := LoadWorkload(path, Menu())
+	assert.NoError(t, err)
+	assert.Len(t, arrivals, 20)
+	for i := 1; i < len(arrivals); i++ {
+		assert.LessOrEqual(t, arrivals[i-1].At, arrivals[i].At)
+	}
+}
+
+func TestNewShopWorkload(t *testing.T) {
+	pourOver, _ := Menu().Item("Pour Over")
+	regular, _ := Menu().Item("Regular")
+	config := DefaultConfig()
+	config.Workload = []Arrival{{At: models.SimSecond, Customer: "Ann", Item: regular}}
+	shop, err := NewShop(config)
+	assert.NoError(t, err)
+	shop.Close()
+	assert.Equal(t, config.Workload, shop.Arrivals)
+
+	// only a drip brewer can't make a pour over
+	config.Workload = append(config.Workload, Arrival{Customer: "Bob", Item: pourOver})
+	_, err = NewShop(config)
+	assert.Error(t, err)
+}
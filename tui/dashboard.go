@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tick is how often the dashboard is redrawn
+const Tick = 100 * models.SimSecond
+
+// the rolling throughput and latency cover this many ticks
+const window = 10
+
+// the most queued orders listed by name
+const queueShown = 5
+
+// clear the screen and move to the top left
+const clearScreen = "\033[H\033[2J"
+
+// sample is the orders served and their wait so far at a tick
+type sample struct {
+	at        time.Time
+	served    int
+	totalWait time.Duration
+}
+
+// rolling is the throughput and latency over the recent ticks
+type rolling struct {
+	span        time.Duration
+	perHour     float64
+	served      int
+	averageWait time.Duration
+}
+
+// Dashboard redraws a live view of the shop every tick
+type Dashboard struct {
+	shop    models.CoffeeShop
+	out     io.Writer
+	lock    *sync.Mutex
+	history []sample
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func New(shop models.CoffeeShop, out io.Writer) *Dashboard {
+	return &Dashboard{
+		shop: shop,
+		out:  out,
+		lock: &sync.Mutex{},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Start redraws the dashboard every tick till it's stopped
+func (d *Dashboard) Start() {
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(Tick)
+		defer ticker.Stop()
+		for {
+			d.draw("")
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops redrawing and draws the shop one last time
+// with the summary under it
+func (d *Dashboard) Stop(summary string) {
+	close(d.stop)
+	<-d.done
+	d.draw(summary)
+}
+
+func (d *Dashboard) draw(summary string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	snapshot := d.shop.Snapshot()
+	d.history = append(d.history, sample{
+		at:        snapshot.At,
+		served:    snapshot.Stats.OrdersServed,
+		totalWait: snapshot.Stats.TotalWait,
+	})
+	if len(d.history) > window+1 {
+		d.history = d.history[len(d.history)-window-1:]
+	}
+
+	render(d.out, snapshot, rollingOver(d.history), summary)
+}
+
+// rollingOver is the throughput and latency between the
+// first and last samples
+func rollingOver(history []sample) rolling {
+	result := rolling{}
+	if len(history) < 2 {
+		return result
+	}
+
+	first, last := history[0], history[len(history)-1]
+	result.span = last.at.Sub(first.at)
+	result.served = last.served - first.served
+	if result.span > 0 {
+		result.perHour = float64(result.served) / (float64(result.span) / float64(models.SimHour))
+	}
+	if result.served > 0 {
+		result.averageWait = (last.totalWait - first.totalWait) / time.Duration(result.served)
+	}
+
+	return result
+}
+
+func render(out io.Writer, s models.Snapshot, r rolling, summary string) {
+	screen := &strings.Builder{}
+	screen.WriteString(clearScreen)
+
+	state := "open"
+	if s.Closed {
+		state = "closed"
+	}
+	fmt.Fprintf(screen, "Coffee shop %s %s\n\n", state, simDuration(s.Stats.OpenTime))
+
+	fmt.Fprintf(screen, "Kiosks      %d free, %d customers waiting\n", s.KiosksFree, s.KioskQueue)
+	fmt.Fprintf(screen, "Queued      %d orders waiting on a barista", len(s.Queued))
+	for i, order := range s.Queued {
+		if i == queueShown {
+			fmt.Fprintf(screen, " ...")
+			break
+		}
+		fmt.Fprintf(screen, "%s #%d %s", separator(i, ":", ","), order.ID, order.Customer)
+	}
+	screen.WriteString("\n\n")
+
+	screen.WriteString("Baristas\n")
+	for _, barista := range s.Baristas {
+		fmt.Fprintf(screen, "  %-14s %d orders\n", barista.Name, len(barista.Orders))
+		for _, order := range barista.Orders {
+			fmt.Fprintf(screen, "    #%-5d %-14s %-16s %s\n", order.ID, order.Customer, order.Item, order.Status)
+		}
+	}
+	screen.WriteString("\n")
+
+	fmt.Fprintf(screen, "Grinders    %d orders waiting\n", s.GrindersWaiting)
+	for _, grinder := range s.Grinders {
+		fmt.Fprintf(screen, "  %-14s %-5s %5.1f%% busy\n", grinder.Grinder, inUse(grinder.InUse), percentOf(grinder.Busy, s.Stats.OpenTime))
+	}
+	fmt.Fprintf(screen, "Brewers     %d orders waiting\n", s.BrewersWaiting)
+	for _, slot := range s.Brewers {
+		name := fmt.Sprintf("%s/%d", slot.Brewer, slot.Slot)
+		fmt.Fprintf(screen, "  %-14s %-5s %5.1f%% busy\n", name, inUse(slot.InUse), percentOf(slot.Busy, s.Stats.OpenTime))
+	}
+	screen.WriteString("\n")
+
+	fmt.Fprintf(screen, "Last %s   %d served, %.1f orders per hour, average wait %s\n",
+		simDuration(r.span), r.served, r.perHour, simDuration(r.averageWait))
+	fmt.Fprintf(screen, "Overall     %d served, %d cancelled, average wait %s\n",
+		s.Stats.OrdersServed, s.Stats.Cancelled, simDuration(s.Stats.AverageWait()))
+
+	if summary != "" {
+		screen.WriteString("\n")
+		screen.WriteString(summary)
+	}
+
+	if _, err := io.WriteString(out, screen.String()); err != nil {
+		fmt.Println("Error drawing the dashboard", err)
+	}
+}
+
+func separator(i int, first string, rest string) string {
+	if i == 0 {
+		return first
+	}
+	return rest
+}
+
+func inUse(busy bool) string {
+	if busy {
+		return "busy"
+	}
+	return "idle"
+}
+
+func percentOf(d time.Duration, of time.Duration) float64 {
+	if of <= 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(of)
+}
+
+// simDuration is a real duration as simulated time, to the second
+func simDuration(d time.Duration) time.Duration {
+	return models.SimDuration(d).Round(time.Second)
+}
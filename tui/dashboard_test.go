@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"blreynolds4/coffeeshop/models"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	snapshot := models.Snapshot{
+		KioskQueue: 3,
+		Queued: []models.OrderSnapshot{
+			{ID: 7, Customer: "Customer-7", Item: "Regular", Status: models.Ordered},
+		},
+		Baristas: []models.BaristaSnapshot{{
+			Name:   "Barista-0",
+			Orders: []models.OrderSnapshot{{ID: 5, Customer: "Customer-5", Item: "Regular", Status: models.Grinding}},
+		}},
+		Grinders:        []models.GrinderUsage{{Grinder: "Grinder-0", Busy: 50 * models.SimSecond, InUse: true}},
+		Brewers:         []models.SlotUsage{{Brewer: "Drip-0", Slot: 0, Busy: 25 * models.SimSecond}},
+		GrindersWaiting: 2,
+		Stats:           models.Stats{OrdersServed: 4, OpenTime: 100 * models.SimSecond},
+	}
+	r := rolling{span: 3600 * models.SimSecond, served: 4, perHour: 4, averageWait: 90 * models.SimSecond}
+
+	out := &bytes.Buffer{}
+	render(out, snapshot, r, "")
+	screen := out.String()
+
+	assert.True(t, strings.HasPrefix(screen, clearScreen))
+	assert.Contains(t, screen, "Coffee shop open 1m40s\n")
+	assert.Contains(t, screen, "0 free, 3 customers waiting")
+	assert.Contains(t, screen, "1 orders waiting on a barista: #7 Customer-7\n")
+	assert.Contains(t, screen, "  Barista-0      1 orders\n")
+	assert.Contains(t, screen, "#5     Customer-5     Regular          Grinding\n")
+	assert.Contains(t, screen, "Grinders    2 orders waiting\n")
+	assert.Contains(t, screen, "  Grinder-0      busy   50.0% busy\n")
+	assert.Contains(t, screen, "  Drip-0/0       idle   25.0% busy\n")
+	assert.Contains(t, screen, "Last 1h0m0s   4 served, 4.0 orders per hour, average wait 1m30s\n")
+
+	// the summary goes under the last view
+	out.Reset()
+	snapshot.Closed = true
+	render(out, snapshot, r, "the summary\n")
+	assert.Contains(t, out.String(), "Coffee shop closed")
+	assert.True(t, strings.HasSuffix(out.String(), "\nthe summary\n"))
+}
+
+func TestRollingOver(t *testing.T) {
+	start := time.Now()
+	history := []sample{
+		{at: start, served: 2, totalWait: 20 * models.SimSecond},
+		{at: start.Add(900 * models.SimSecond), served: 3, totalWait: 40 * models.SimSecond},
+		{at: start.Add(1800 * models.SimSecond), served: 6, totalWait: 100 * models.SimSecond},
+	}
+
+	r := rollingOver(history)
+	assert.Equal(t, 1800*models.SimSecond, r.span)
+	assert.Equal(t, 4, r.served)
+	assert.InDelta(t, 8.0, r.perHour, 0.001)
+	assert.Equal(t, 20*models.SimSecond, r.averageWait)
+
+	assert.Equal(t, rolling{}, rollingOver(history[:1]))
+}
+
+func TestSimDuration(t *testing.T) {
+	assert.Equal(t, 90*time.Second, simDuration(90*models.SimSecond))
+
+	// a long day doesn't overflow
+	assert.Equal(t, 8*time.Hour, simDuration(8*3600*models.SimSecond))
+}
+
+func TestDashboard(t *testing.T) {
+	menu := models.Menu{{Name: "Regular", Size: 240, CoffeeRatio: 16, Methods: []models.BrewMethod{models.Drip}}}
+	shop := models.NewCoffeeShop(menu, 1, 1, 1,
+		models.NewGrinderPool(models.NewGrinder(100)),
+		models.NewBrewerPool(models.NewBrewer(100)))
+
+	out := &bytes.Buffer{}
+	dashboard := New(shop, out)
+	dashboard.Start()
+
+	kiosk := shop.WaitForOrderingKiosk()
+	order := kiosk.CreateOrder("test", menu[0])
+	shop.LeaveOrderingKiosk(kiosk)
+	order.Wait()
+	shop.Close()
+	dashboard.Stop(shop.Stats().String())
+
+	// the last screen is the closed shop and its stats
+	screens := strings.Split(out.String(), clearScreen)
+	last := screens[len(screens)-1]
+	assert.Contains(t, last, "Coffee shop closed")
+	assert.Contains(t, last, "Overall     1 served")
+	assert.Contains(t, last, "\nOrders served 1\n")
+}
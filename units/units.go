@@ -0,0 +1,87 @@
+// Package units has the physical quantities used to make coffee
+// so grams, milliliters and ounces can't be mixed up
+package units
+
+import (
+	"math"
+	"strconv"
+)
+
+// MillilitersPerFluidOunce is the size of a US fluid ounce
+const MillilitersPerFluidOunce = 29.5735
+
+type Grams float64
+
+type Milliliters float64
+
+type FluidOunces float64
+
+// GramsPerSecond is how fast a grinder works
+type GramsPerSecond float64
+
+// MillilitersPerSecond is how fast a brewer pours
+type MillilitersPerSecond float64
+
+func (g Grams) String() string {
+	return format(float64(g)) + "g"
+}
+
+func (ml Milliliters) FluidOunces() FluidOunces {
+	return FluidOunces(float64(ml) / MillilitersPerFluidOunce)
+}
+
+func (ml Milliliters) String() string {
+	return format(float64(ml)) + "ml"
+}
+
+func (oz FluidOunces) Milliliters() Milliliters {
+	return Milliliters(float64(oz) * MillilitersPerFluidOunce)
+}
+
+func (oz FluidOunces) String() string {
+	return format(float64(oz)) + " fl oz"
+}
+
+func (gs GramsPerSecond) String() string {
+	return format(float64(gs)) + "g/s"
+}
+
+func (ms MillilitersPerSecond) String() string {
+	return format(float64(ms)) + "ml/s"
+}
+
+// Ratio is the milliliters of water brewed for each gram of coffee,
+// 16.5 is written 1:16.5.  A smaller ratio is a stronger coffee.
+type Ratio float64
+
+// Coffee is how much coffee it takes to brew the water at the ratio
+func (r Ratio) Coffee(water Milliliters) Grams {
+	if r <= 0 {
+		return 0
+	}
+
+	return Grams(float64(water) / float64(r))
+}
+
+// Water is how much the coffee brews at the ratio
+func (r Ratio) Water(coffee Grams) Milliliters {
+	return Milliliters(float64(coffee) * float64(r))
+}
+
+func (r Ratio) String() string {
+	return "1:" + format(float64(r))
+}
+
+// RatioOf is the ratio the water and coffee were brewed at
+func RatioOf(water Milliliters, coffee Grams) Ratio {
+	if coffee <= 0 {
+		return 0
+	}
+
+	return Ratio(float64(water) / float64(coffee))
+}
+
+// format rounds to a tenth and drops trailing zeros
+func format(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+}
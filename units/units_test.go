@@ -0,0 +1,34 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversions(t *testing.T) {
+	assert.InDelta(t, 236.588, float64(FluidOunces(8).Milliliters()), 0.001)
+	assert.InDelta(t, 12, float64(Milliliters(354.882).FluidOunces()), 0.001)
+}
+
+func TestRatio(t *testing.T) {
+	r := Ratio(16.5)
+
+	assert.InDelta(t, 20, float64(r.Coffee(330)), 0.001)
+	assert.InDelta(t, 330, float64(r.Water(20)), 0.001)
+	assert.InDelta(t, 16.5, float64(RatioOf(330, 20)), 0.001)
+
+	// no ratio makes no coffee
+	assert.Equal(t, Grams(0), Ratio(0).Coffee(330))
+	assert.Equal(t, Ratio(0), RatioOf(330, 0))
+}
+
+func TestFormatting(t *testing.T) {
+	assert.Equal(t, "12.5g", Grams(12.48).String())
+	assert.Equal(t, "240ml", Milliliters(240).String())
+	assert.Equal(t, "8 fl oz", FluidOunces(8).String())
+	assert.Equal(t, "1:16.5", Ratio(16.5).String())
+	assert.Equal(t, "1:15", Ratio(15).String())
+	assert.Equal(t, "2.5g/s", GramsPerSecond(2.5).String())
+	assert.Equal(t, "4ml/s", MillilitersPerSecond(4).String())
+}